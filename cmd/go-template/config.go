@@ -0,0 +1,38 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand, expect one of print, validate")
+	}
+
+	subcommand, args := args[0], args[1:]
+	if subcommand != "print" && subcommand != "validate" {
+		return errors.Errorf("unknown subcommand %q, expect one of print, validate", subcommand)
+	}
+	flagSet := flag.NewFlagSet("config "+subcommand, flag.ExitOnError)
+	configFile := flagSet.String("config", "", "path of the YAML or TOML configuration file, overrides CONFIG_FILE")
+	_ = flagSet.Parse(args)
+
+	if err := conf.Init(conf.WithFile(*configFile)); err != nil {
+		return errors.Wrap(err, "initialize configuration")
+	}
+	if subcommand == "validate" {
+		fmt.Println("Configuration is valid")
+		return nil
+	}
+	return conf.Print(os.Stdout)
+}
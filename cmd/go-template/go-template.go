@@ -5,73 +5,55 @@
 package main
 
 import (
-	"context"
-	"errors"
-	"flag"
 	"fmt"
-	"net"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
 	"github.com/sirupsen/logrus"
-
-	"github.com/wuhan005/go-template/internal/conf"
-	"github.com/wuhan005/go-template/internal/db"
-	"github.com/wuhan005/go-template/internal/route"
 )
 
-func main() {
-	host := flag.String("host", "0.0.0.0", "host to listen")
-	port := flag.Int("port", 8000, "port to listen")
-	flag.Parse()
-
-	if err := conf.Init(); err != nil {
-		logrus.WithError(err).Fatal("Failed to initialize configuration")
-	}
-
-	db, err := db.Init()
-	if err != nil {
-		logrus.WithError(err).Fatal("Failed to initialize database")
-	}
-
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
+// command is a subcommand of the binary.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
 
-	c := make(chan os.Signal, 1)
-	// Trigger graceful shutdown on SIGINT or SIGTERM.
-	// The default signal sent by the `kill` command is SIGTERM,
-	// which is taken as the graceful shutdown signal for many systems, e.g. Kubernetes, Gunicorn.
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+var commands = []command{
+	{"serve", "Start the HTTP server (default)", runServe},
+	{"migrate", "Manage database migrations: up|down|status", runMigrate},
+	{"user", "Manage users: create|reset-password|delete|unlock, passwords are read from stdin", runUser},
+	{"config", "Inspect the configuration: print|validate", runConfig},
+	{"version", "Print the version information", runVersion},
+}
 
-	address := fmt.Sprintf("%s:%d", *host, *port)
-	listener, err := net.Listen("tcp", address)
-	if err != nil {
-		logrus.WithError(err).Fatal("Failed to listen")
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
 	}
+}
 
-	f := route.New(db)
-	server := http.Server{
-		Handler:           f,
-		ReadHeaderTimeout: 3 * time.Second,
+func main() {
+	// Keep `go-template -port 8000` working by defaulting to the serve command.
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	go func() {
-		<-c
-		_ = server.Shutdown(ctx)
-		cancel()
-	}()
-
-	logrus.WithField("address", address).Info("Server is running")
-	if err := server.Serve(listener); err != nil {
-		if !errors.Is(err, http.ErrServerClosed) {
-			logrus.WithError(err).Error("Failed to serve")
-			cancel()
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(args); err != nil {
+			logrus.WithError(err).Fatalf("Failed to run %q command", name)
 		}
+		return
 	}
 
-	// Wait for CTRL-C.
-	<-ctx.Done()
+	if name != "help" {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	}
+	usage()
+	os.Exit(2)
 }
@@ -0,0 +1,103 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/db"
+	"github.com/wuhan005/go-template/internal/logging"
+	"github.com/wuhan005/go-template/internal/migrate"
+)
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand, expect one of up, down, status")
+	}
+
+	subcommand, args := args[0], args[1:]
+	if subcommand != "up" && subcommand != "down" && subcommand != "status" {
+		return errors.Errorf("unknown subcommand %q, expect one of up, down, status", subcommand)
+	}
+	flagSet := flag.NewFlagSet("migrate "+subcommand, flag.ExitOnError)
+	configFile := flagSet.String("config", "", "path of the YAML or TOML configuration file, overrides CONFIG_FILE")
+	var steps *int
+	if subcommand == "down" {
+		steps = flagSet.Int("steps", 1, "number of migrations to revert")
+	}
+	_ = flagSet.Parse(args)
+
+	if err := conf.Init(conf.WithFile(*configFile)); err != nil {
+		return errors.Wrap(err, "initialize configuration")
+	}
+	if err := logging.Init(); err != nil {
+		return errors.Wrap(err, "initialize logging")
+	}
+
+	gormDB, err := db.Open()
+	if err != nil {
+		return errors.Wrap(err, "open database")
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return errors.Wrap(err, "get db")
+	}
+	defer func() { _ = sqlDB.Close() }()
+
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		return errors.Wrap(err, "new migrator")
+	}
+
+	ctx := context.Background()
+	switch subcommand {
+	case "up":
+		migrations, err := migrator.Up(ctx)
+		if err != nil {
+			return errors.Wrap(err, "up")
+		}
+		for _, migration := range migrations {
+			fmt.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if len(migrations) == 0 {
+			fmt.Println("Database schema is up to date")
+		}
+
+	case "down":
+		migrations, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return errors.Wrap(err, "down")
+		}
+		for _, migration := range migrations {
+			fmt.Printf("Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return errors.Wrap(err, "status")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/db"
+	"github.com/wuhan005/go-template/internal/health"
+	"github.com/wuhan005/go-template/internal/lifecycle"
+	"github.com/wuhan005/go-template/internal/logging"
+	"github.com/wuhan005/go-template/internal/metrics"
+	"github.com/wuhan005/go-template/internal/ratelimit"
+	"github.com/wuhan005/go-template/internal/redis"
+	"github.com/wuhan005/go-template/internal/route"
+	"github.com/wuhan005/go-template/internal/server"
+	"github.com/wuhan005/go-template/internal/tracing"
+)
+
+func runServe(args []string) error {
+	flagSet := flag.NewFlagSet("serve", flag.ExitOnError)
+	configFile := flagSet.String("config", "", "path of the YAML or TOML configuration file, overrides CONFIG_FILE")
+	flagSet.String("host", "0.0.0.0", "host to listen, overrides SERVER_HOST")
+	flagSet.Int("port", 8000, "port to listen, overrides SERVER_PORT")
+	_ = flagSet.Parse(args)
+
+	// Only the flags explicitly set take precedence over the other sources.
+	overrides := make(map[string]string)
+	flagSet.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "host":
+			overrides["SERVER_HOST"] = f.Value.String()
+		case "port":
+			overrides["SERVER_PORT"] = f.Value.String()
+		}
+	})
+	if err := conf.Init(conf.WithFile(*configFile), conf.WithOverrides(overrides)); err != nil {
+		return errors.Wrap(err, "initialize configuration")
+	}
+	if err := logging.Init(); err != nil {
+		return errors.Wrap(err, "initialize logging")
+	}
+
+	otelShutdown, err := tracing.SetupOTelSDK(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "set up OpenTelemetry SDK")
+	}
+
+	db, err := db.Init()
+	if err != nil {
+		return errors.Wrap(err, "initialize database")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return errors.Wrap(err, "get database")
+	}
+	if conf.Metrics.Enabled {
+		if err := metrics.RegisterDB(sqlDB, db.Name()); err != nil {
+			return errors.Wrap(err, "register database metrics")
+		}
+	}
+
+	var redisClient *goredis.Client
+	if conf.Redis.Address != "" {
+		redisClient, err = redis.Init()
+		if err != nil {
+			return errors.Wrap(err, "initialize redis")
+		}
+	}
+
+	var limiter ratelimit.Limiter
+	if conf.RateLimit.Enabled {
+		limiter, err = ratelimit.New(conf.RateLimit.Backend, redisClient)
+		if err != nil {
+			return errors.Wrap(err, "create rate limiter")
+		}
+	}
+
+	// Trigger graceful shutdown on SIGINT or SIGTERM, which are caught before the
+	// server starts so that an early signal doesn't kill the process.
+	// The default signal sent by the `kill` command is SIGTERM,
+	// which is taken as the graceful shutdown signal for many systems, e.g. Kubernetes, Gunicorn.
+	lc := lifecycle.New(conf.Server.PreStopDelay, os.Interrupt, syscall.SIGTERM)
+
+	f := route.New(db, redisClient, limiter, lc)
+	httpServer, err := server.New(f)
+	if err != nil {
+		return errors.Wrap(err, "new server")
+	}
+
+	address := net.JoinHostPort(conf.Server.Host, strconv.Itoa(conf.Server.Port))
+	listener, err := server.Listen(address)
+	if err != nil {
+		return errors.Wrap(err, "listen")
+	}
+	lc.OnShutdown("http server", conf.Server.ShutdownTimeout, server.Shutdown(httpServer))
+
+	var adminServer *http.Server
+	var adminListener net.Listener
+	if conf.Admin.Enabled {
+		mux := http.NewServeMux()
+		if conf.Metrics.Enabled {
+			mux.Handle(conf.Metrics.Path, metrics.Handler())
+		}
+		// The detailed probes are only served to the operators on the admin listener.
+		mux.Handle("/livez", health.AdminHandler(health.Liveness))
+		mux.Handle("/readyz", health.AdminHandler(health.Readiness))
+		mux.Handle("/startupz", health.AdminHandler(health.Startup))
+		adminServer = &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: conf.Server.ReadHeaderTimeout,
+		}
+
+		adminListener, err = net.Listen("tcp", conf.Admin.Address)
+		if err != nil {
+			_ = listener.Close()
+			return errors.Wrap(err, "listen admin")
+		}
+		lc.OnShutdown("admin server", conf.Server.ShutdownTimeout, server.Shutdown(adminServer))
+	}
+
+	lc.OnShutdown("database", 0, func(context.Context) error { return sqlDB.Close() })
+	if redisClient != nil {
+		lc.OnShutdown("redis", 0, func(context.Context) error { return redisClient.Close() })
+	}
+	// Flush the buffered spans and metrics before exiting.
+	lc.OnShutdown("telemetry", 10*time.Second, otelShutdown)
+
+	go func() {
+		logrus.WithFields(logrus.Fields{
+			"address": listener.Addr().String(),
+			"network": listener.Addr().Network(),
+			"tls":     server.TLSEnabled(),
+		}).Info("Server is running")
+		if err := server.Serve(httpServer, listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Error("Failed to serve")
+			lc.Stop()
+		}
+	}()
+	if adminServer != nil {
+		go func() {
+			logrus.WithField("address", adminListener.Addr().String()).Info("Admin server is running")
+			if err := adminServer.Serve(adminListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.WithError(err).Error("Failed to serve admin server")
+				lc.Stop()
+			}
+		}()
+	}
+	lc.SetReady(true)
+
+	if err := lc.Run(); err != nil {
+		return errors.Wrap(err, "shut down")
+	}
+	return nil
+}
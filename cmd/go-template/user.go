@@ -0,0 +1,168 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/db"
+	"github.com/wuhan005/go-template/internal/logging"
+)
+
+func runUser(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand, expect one of create, reset-password, delete, unlock")
+	}
+
+	subcommand, args := args[0], args[1:]
+	flagSet := flag.NewFlagSet("user "+subcommand, flag.ExitOnError)
+	configFile := flagSet.String("config", "", "path of the YAML or TOML configuration file, overrides CONFIG_FILE")
+	email := flagSet.String("email", "", "email of the user")
+	var nickName, role *string
+	var needPassword bool
+	switch subcommand {
+	case "create":
+		needPassword = true
+		nickName = flagSet.String("nickname", "", "nickname of the user")
+		role = flagSet.String("role", "", "role to grant to the user, e.g. admin")
+	case "reset-password":
+		needPassword = true
+	case "delete", "unlock":
+	default:
+		return errors.Errorf("unknown subcommand %q, expect one of create, reset-password, delete, unlock", subcommand)
+	}
+	_ = flagSet.Parse(args)
+
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	// The password is never taken from the arguments, which are visible in the
+	// process list and the shell history.
+	var password string
+	if needPassword {
+		var err error
+		password, err = readPassword()
+		if err != nil {
+			return errors.Wrap(err, "read password")
+		}
+		if password == "" {
+			return errors.New("password is required")
+		}
+	}
+
+	if err := conf.Init(conf.WithFile(*configFile)); err != nil {
+		return errors.Wrap(err, "initialize configuration")
+	}
+	if err := logging.Init(); err != nil {
+		return errors.Wrap(err, "initialize logging")
+	}
+	gormDB, err := db.Init()
+	if err != nil {
+		return errors.Wrap(err, "initialize database")
+	}
+
+	ctx := context.Background()
+	if subcommand == "create" {
+		// The user is not left behind without the role if the role fails to be
+		// assigned, e.g. when the role doesn't exist.
+		var user *db.User
+		err := gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			user, err = db.NewUsersStore(tx).Create(ctx, db.CreateUserOptions{
+				Email:    *email,
+				Password: password,
+				NickName: *nickName,
+			})
+			if err != nil {
+				return errors.Wrap(err, "create user")
+			}
+			if *role != "" {
+				if err := db.NewRolesStore(tx).AssignToUser(ctx, user.ID, *role); err != nil {
+					return errors.Wrapf(err, "assign role %q", *role)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created user %q (%s)\n", user.Email, user.UID)
+		return nil
+	}
+
+	user, err := db.Users.GetByEmail(ctx, *email)
+	if err != nil {
+		return errors.Wrap(err, "get user")
+	}
+
+	switch subcommand {
+	case "reset-password":
+		if err := db.Users.ChangePassword(ctx, user.ID, password); err != nil {
+			return errors.Wrap(err, "change password")
+		}
+		if err := db.Sessions.DeleteByUserID(ctx, user.ID); err != nil {
+			return errors.Wrap(err, "delete sessions")
+		}
+		fmt.Printf("Reset password of user %q\n", user.Email)
+
+	case "delete":
+		if err := db.Users.Delete(ctx, user.ID); err != nil {
+			return errors.Wrap(err, "delete user")
+		}
+		fmt.Printf("Deleted user %q\n", user.Email)
+
+	case "unlock":
+		if err := db.LoginAttempts.Reset(ctx, db.EmailLoginKey(user.Email)); err != nil {
+			return errors.Wrap(err, "reset failed sign-in attempts")
+		}
+		fmt.Printf("Unlocked user %q\n", user.Email)
+	}
+	return nil
+}
+
+// readPassword prompts for the password twice without echo if the standard
+// input is a terminal, otherwise it reads the first line of the standard input,
+// e.g. `go-template user create -email ... < password.txt`.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	prompt := func(prompt string) (string, error) {
+		_, _ = fmt.Fprint(os.Stderr, prompt)
+		password, err := term.ReadPassword(fd)
+		_, _ = fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+	password, err := prompt("Password: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := prompt("Confirm password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", errors.New("passwords do not match")
+	}
+	return password, nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/wuhan005/go-template/internal/appconst"
+)
+
+func runVersion(_ []string) error {
+	commit := appconst.BuildCommit
+	if commit == "" {
+		commit = "unknown"
+	}
+	date := appconst.BuildDate
+	if date == "" {
+		date = "unknown"
+	}
+
+	fmt.Printf("Commit: %s\nDate:   %s\nGo:     %s\n", commit, date, runtime.Version())
+	return nil
+}
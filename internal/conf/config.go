@@ -5,46 +5,230 @@
 package conf
 
 import (
-	"github.com/kelseyhightower/envconfig"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"time"
+
 	"github.com/pkg/errors"
 )
 
 var App struct {
-	IpHeader string `envconfig:"IP_HEADER"`
+	// IpHeader is the header carrying the client IP set by the trusted
+	// proxies, e.g. X-Forwarded-For, X-Real-IP or Forwarded. The forwarding
+	// headers are ignored if it is empty. It must be set together with
+	// TrustedProxies, unless the server listens on a Unix socket.
+	IpHeader string `env:"IP_HEADER"`
+	// TrustedProxies is the comma-separated CIDRs of the proxies whose
+	// forwarding headers are trusted, e.g. "10.0.0.0/8,127.0.0.1/32".
+	TrustedProxies []netip.Prefix `env:"APP_TRUSTED_PROXIES"`
+	// SecretKey is used to sign the values handed out to clients, e.g. pagination cursors.
+	SecretKey string `env:"APP_SECRET_KEY" secret:"true"`
+	// ProblemDetails writes the error responses in the RFC 7807 format
+	// (application/problem+json). Clients can also opt in with the Accept header.
+	ProblemDetails bool `env:"APP_PROBLEM_DETAILS"`
+	// MaxBodySize is the maximum size of the request body in bytes.
+	MaxBodySize int64 `env:"APP_MAX_BODY_SIZE" default:"10485760"`
+	// DefaultLocale is the locale of the messages when the Accept-Language
+	// header doesn't match any supported locale, one of en and zh.
+	DefaultLocale string `env:"APP_DEFAULT_LOCALE" default:"zh" options:"en,zh"`
+}
+
+var Server struct {
+	// Host and Port are the TCP address to listen on, which can also be set
+	// by the -host and -port flags of the serve command.
+	Host string `env:"SERVER_HOST" default:"0.0.0.0"`
+	Port int    `env:"SERVER_PORT" default:"8000"`
+
+	// PreStopDelay is the time to wait after the server is marked not ready
+	// before it stops accepting connections.
+	PreStopDelay time.Duration `env:"SERVER_PRE_STOP_DELAY" default:"5s"`
+	// ShutdownTimeout is the deadline to drain the in-flight requests.
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
+
+	ReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"30s"`
+	ReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" default:"3s"`
+	WriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"60s"`
+	IdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"120s"`
+	MaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+
+	// HTTP2 enables HTTP/2 over TLS, and over cleartext (h2c) if H2C is also
+	// enabled, e.g. behind a proxy speaking HTTP/2 to the upstreams.
+	HTTP2 bool `env:"SERVER_HTTP2" default:"true"`
+	H2C   bool `env:"SERVER_H2C"`
+
+	// UnixSocket is the path of the Unix socket to listen on instead of TCP.
+	// The socket passed by systemd socket activation takes precedence over both.
+	UnixSocket     string      `env:"SERVER_UNIX_SOCKET"`
+	UnixSocketMode os.FileMode `env:"SERVER_UNIX_SOCKET_MODE" default:"0660"`
+
+	// TLSCertFile and TLSKeyFile enable TLS. The certificate is reloaded when
+	// the files change, which are checked at most once per TLSReloadInterval.
+	TLSCertFile       string        `env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile        string        `env:"SERVER_TLS_KEY_FILE"`
+	TLSReloadInterval time.Duration `env:"SERVER_TLS_RELOAD_INTERVAL" default:"10s"`
+	// TLSMinVersion is one of 1.2 and 1.3.
+	TLSMinVersion string `env:"SERVER_TLS_MIN_VERSION" default:"1.2" options:"1.2,1.3"`
+	// TLSClientCAFile is the CA bundle to verify the client certificates
+	// (mTLS). TLSClientAuth is one of require and optional, the latter only
+	// verifies the certificates the clients present.
+	TLSClientCAFile string `env:"SERVER_TLS_CLIENT_CA_FILE"`
+	TLSClientAuth   string `env:"SERVER_TLS_CLIENT_AUTH" default:"require" options:"require,optional"`
 }
 
 var Postgres struct {
-	DSN string `envconfig:"POSTGRES_DSN"`
+	DSN string `env:"POSTGRES_DSN" secret:"true" required:"true"`
+	// AutoMigrate applies the pending migrations at startup. When disabled, the
+	// server refuses to start if the database schema is behind.
+	AutoMigrate bool `env:"POSTGRES_AUTO_MIGRATE" default:"true"`
 }
 
 var Redis struct {
-	Address  string `envconfig:"REDIS_ADDRESS"`
-	Username string `envconfig:"REDIS_USERNAME"`
-	Password string `envconfig:"REDIS_PASSWORD"`
-	Database int    `envconfig:"REDIS_DATABASE"`
+	Address  string `env:"REDIS_ADDRESS"`
+	Username string `env:"REDIS_USERNAME"`
+	Password string `env:"REDIS_PASSWORD" secret:"true"`
+	Database int    `env:"REDIS_DATABASE"`
+}
+
+var Password struct {
+	// Algorithm is used to hash new passwords, one of argon2id, bcrypt and scrypt.
+	// Existing hashes of other algorithms or parameters are upgraded on sign-in.
+	Algorithm string `env:"PASSWORD_ALGORITHM" default:"argon2id" options:"argon2id,bcrypt,scrypt"`
+	// Pepper is mixed into every password before hashing. Changing it
+	// invalidates all the existing password hashes.
+	Pepper string `env:"PASSWORD_PEPPER" secret:"true"`
+	// Argon2Memory is the memory cost of argon2id in KiB.
+	Argon2Memory  uint32 `env:"PASSWORD_ARGON2_MEMORY" default:"65536"`
+	Argon2Time    uint32 `env:"PASSWORD_ARGON2_TIME" default:"3"`
+	Argon2Threads uint8  `env:"PASSWORD_ARGON2_THREADS" default:"2"`
+	BcryptCost    int    `env:"PASSWORD_BCRYPT_COST" default:"12"`
+	// ScryptLogN is the base-2 logarithm of the scrypt CPU/memory cost N.
+	ScryptLogN int `env:"PASSWORD_SCRYPT_LOG_N" default:"15"`
+	ScryptR    int `env:"PASSWORD_SCRYPT_R" default:"8"`
+	ScryptP    int `env:"PASSWORD_SCRYPT_P" default:"1"`
+}
+
+var Session struct {
+	CookieName string        `env:"SESSION_COOKIE_NAME" default:"go_template_session"`
+	MaxAge     time.Duration `env:"SESSION_MAX_AGE" default:"168h"`
+	Secure     bool          `env:"SESSION_SECURE"`
 }
 
 var Tracing struct {
-	Endpoint    string `envconfig:"TRACING_ENDPOINT"`
-	Token       string `envconfig:"TRACING_TOKEN"`
-	ServiceName string `envconfig:"TRACING_SERVICE_NAME"`
-	HostName    string `envconfig:"HOSTNAME"`
+	// Exporter is one of otlp-grpc, otlp-http, stdout and none. The OTLP
+	// exporters are disabled when no endpoint is configured.
+	Exporter    string `env:"TRACING_EXPORTER" default:"otlp-grpc" options:"otlp-grpc,otlp-http,stdout,none"`
+	Endpoint    string `env:"TRACING_ENDPOINT"`
+	Token       string `env:"TRACING_TOKEN" secret:"true"`
+	ServiceName string `env:"TRACING_SERVICE_NAME" default:"go-template"`
+	HostName    string `env:"HOSTNAME"`
+	// SamplerRatio is the ratio of the root spans to be sampled, from 0 to 1.
+	SamplerRatio float64 `env:"TRACING_SAMPLER_RATIO" default:"1"`
+	// Insecure disables TLS of the OTLP exporters.
+	Insecure bool `env:"TRACING_INSECURE" default:"true"`
+	// CAFile is the path of the CA certificate to verify the OTLP endpoint.
+	CAFile string `env:"TRACING_CA_FILE"`
 }
 
-// Init initializes the configuration by reading environment variables.
-func Init() error {
-	if err := envconfig.Process("", &App); err != nil {
-		return errors.Wrap(err, "parse app")
-	}
-	if err := envconfig.Process("", &Postgres); err != nil {
-		return errors.Wrap(err, "parse postgres")
+var Lockout struct {
+	// MaxAttempts is the number of consecutive failed sign-ins to lock an
+	// account, 0 disables the lockout of accounts.
+	MaxAttempts int `env:"LOCKOUT_MAX_ATTEMPTS" default:"5"`
+	// IPMaxAttempts is the number of consecutive failed sign-ins to lock a
+	// client IP, 0 disables the lockout of IPs.
+	IPMaxAttempts int `env:"LOCKOUT_IP_MAX_ATTEMPTS" default:"20"`
+	// Duration is the duration of the first lockout, which is doubled for each
+	// subsequent lockout up to MaxDuration.
+	Duration    time.Duration `env:"LOCKOUT_DURATION" default:"15m"`
+	MaxDuration time.Duration `env:"LOCKOUT_MAX_DURATION" default:"24h"`
+	// Window is the time after the last failed sign-in, or the end of the last
+	// lockout, to forget the failures.
+	Window time.Duration `env:"LOCKOUT_WINDOW" default:"1h"`
+}
+
+var RateLimit struct {
+	Enabled bool `env:"RATE_LIMIT_ENABLED" default:"true"`
+	// Backend is one of memory and redis. The redis backend shares the limits
+	// between the instances, which requires the Redis address.
+	Backend string `env:"RATE_LIMIT_BACKEND" default:"memory" options:"memory,redis"`
+}
+
+var Log struct {
+	// Level is one of trace, debug, info, warn, error, fatal and panic.
+	Level string `env:"LOG_LEVEL" default:"info" options:"trace,debug,info,warn,error,fatal,panic"`
+	// Format is one of text and json.
+	Format string `env:"LOG_FORMAT" default:"text" options:"text,json"`
+}
+
+var Metrics struct {
+	// Enabled exposes the metrics in the Prometheus format, which are served by
+	// the admin listener.
+	Enabled bool   `env:"METRICS_ENABLED" default:"true"`
+	Path    string `env:"METRICS_PATH" default:"/metrics"`
+	// Public also serves the metrics by the main server, which exposes them to
+	// anyone who can reach the application.
+	Public bool `env:"METRICS_PUBLIC"`
+}
+
+var Admin struct {
+	// Enabled starts the separate admin listener to serve the metrics and the
+	// detailed health probes to the operators.
+	Enabled bool `env:"ADMIN_ENABLED" default:"true"`
+	// Address is the TCP address of the admin listener, which should not be
+	// reachable by the public.
+	Address string `env:"ADMIN_ADDRESS" default:"127.0.0.1:9090"`
+}
+
+// sections is the list of configuration sections in the order they are parsed.
+var sections = []struct {
+	name string
+	ptr  interface{}
+}{
+	{"app", &App},
+	{"server", &Server},
+	{"postgres", &Postgres},
+	{"redis", &Redis},
+	{"password", &Password},
+	{"session", &Session},
+	{"lockout", &Lockout},
+	{"rate_limit", &RateLimit},
+	{"log", &Log},
+	{"tracing", &Tracing},
+	{"metrics", &Metrics},
+	{"admin", &Admin},
+}
+
+// Init initializes the configuration, see Option for the sources and their
+// precedence. All the problems of the configuration are reported at once by a
+// *ValidationError.
+func Init(opts ...Option) error {
+	o := newOptions(opts)
+
+	var file map[string]map[string]interface{}
+	if o.file != "" {
+		var err error
+		file, err = readFile(o.file)
+		if err != nil {
+			return errors.Wrap(err, "read config file")
+		}
 	}
-	if err := envconfig.Process("", &Redis); err != nil {
-		return errors.Wrap(err, "parse redis")
+
+	var problems []string
+	for _, section := range sections {
+		problems = append(problems, load(section.name, section.ptr, file[section.name], o)...)
+		delete(file, section.name)
 	}
-	if err := envconfig.Process("", &Tracing); err != nil {
-		return errors.Wrap(err, "parse tracing")
+	unknown := make([]string, 0, len(file))
+	for name := range file {
+		unknown = append(unknown, fmt.Sprintf("unknown section %q in %s", name, o.file))
 	}
+	sort.Strings(unknown)
+	problems = append(problems, unknown...)
+	problems = append(problems, validate()...)
 
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
 	return nil
 }
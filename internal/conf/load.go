@@ -0,0 +1,312 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conf
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// options is a group of options for loading the configuration.
+type options struct {
+	file      string
+	overrides map[string]string
+}
+
+// Option applies an option value for loading the configuration.
+//
+// The value of a field is taken from the first of the following sources:
+//  1. The overrides, e.g. the command-line flags.
+//  2. The environment variable named by the `env` tag.
+//  3. The file named by the environment variable with the "_FILE" suffix,
+//     e.g. POSTGRES_DSN_FILE for the Docker and Kubernetes secrets.
+//  4. The configuration file, where the fields are keyed by the section name
+//     and the snake-cased field name, e.g. server.read_timeout.
+//  5. The `default` tag.
+type Option interface {
+	apply(*options)
+}
+
+// newOptions returns the options configured with all the passed Options.
+func newOptions(opts []Option) *options {
+	o := &options{
+		file: os.Getenv("CONFIG_FILE"),
+	}
+	for _, opt := range opts {
+		opt.apply(o)
+	}
+	return o
+}
+
+type fileOption string
+
+func (o fileOption) apply(opts *options) {
+	if o != "" {
+		opts.file = string(o)
+	}
+}
+
+// WithFile returns an Option to read the configuration file of the path, which
+// is in the YAML or TOML format by its extension. It defaults to the value of
+// the CONFIG_FILE environment variable.
+func WithFile(path string) Option {
+	return fileOption(path)
+}
+
+type overridesOption map[string]string
+
+func (o overridesOption) apply(opts *options) {
+	if opts.overrides == nil {
+		opts.overrides = make(map[string]string, len(o))
+	}
+	for key, value := range o {
+		opts.overrides[key] = value
+	}
+}
+
+// WithOverrides returns an Option to override the values keyed by the
+// environment variable names, e.g. {"SERVER_PORT": "8080"}.
+func WithOverrides(overrides map[string]string) Option {
+	return overridesOption(overrides)
+}
+
+// readFile reads the sections of the configuration file.
+func readFile(path string) (map[string]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read")
+	}
+
+	var raw map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, errors.Wrap(err, "decode YAML")
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return nil, errors.Wrap(err, "decode TOML")
+		}
+	default:
+		return nil, errors.Errorf("unsupported file extension %q, expect one of .yaml, .yml, .toml", ext)
+	}
+
+	file := make(map[string]map[string]interface{}, len(raw))
+	for name, value := range raw {
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("section %q is not a table", name)
+		}
+		file[name] = section
+	}
+	return file, nil
+}
+
+// load loads the fields of the section, returning the problems of the values.
+func load(name string, ptr interface{}, fileValues map[string]interface{}, o *options) []string {
+	var problems []string
+	seen := make(map[string]bool)
+
+	v := reflect.ValueOf(ptr).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		fileKey := snakeCase(field.Name)
+		seen[fileKey] = true
+
+		value, source, ok, err := lookup(key, name+"."+fileKey, fileValues[fileKey], o)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if !ok {
+			value, source = field.Tag.Get("default"), key
+		}
+
+		fieldValue := v.Field(i)
+		fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		if value == "" {
+			if field.Tag.Get("required") == "true" {
+				problems = append(problems, fmt.Sprintf("%s is required", key))
+			}
+			continue
+		}
+
+		if options := field.Tag.Get("options"); options != "" && !contains(strings.Split(options, ","), value) {
+			problems = append(problems, fmt.Sprintf("%s must be one of %s, got %q", source, strings.ReplaceAll(options, ",", ", "), value))
+			continue
+		}
+		if err := setValue(fieldValue, value); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid %s: %v", source, err))
+		}
+	}
+
+	unknown := make([]string, 0, len(fileValues))
+	for fileKey := range fileValues {
+		if !seen[fileKey] {
+			unknown = append(unknown, fmt.Sprintf("unknown key %s.%s in %s", name, fileKey, o.file))
+		}
+	}
+	sort.Strings(unknown)
+	return append(problems, unknown...)
+}
+
+// lookup returns the value of the field from the sources in the order of
+// precedence, and the description of the source it is taken from.
+func lookup(key, fileKey string, fileValue interface{}, o *options) (value, source string, ok bool, err error) {
+	if value, ok := o.overrides[key]; ok {
+		return value, key, true, nil
+	}
+
+	value, ok = os.LookupEnv(key)
+	path, fromFile := os.LookupEnv(key + "_FILE")
+	if ok && fromFile {
+		return "", "", false, errors.Errorf("%s and %s_FILE are mutually exclusive", key, key)
+	}
+	if ok {
+		return value, key, true, nil
+	}
+	if fromFile {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", false, errors.Errorf("read %s_FILE: %v", key, err)
+		}
+		// Secrets are usually written with a trailing newline.
+		return strings.TrimRight(string(data), "\r\n"), key + "_FILE", true, nil
+	}
+
+	if fileValue != nil {
+		value, err := formatFileValue(fileValue)
+		if err != nil {
+			return "", "", false, errors.Errorf("invalid %s in %s: %v", fileKey, o.file, err)
+		}
+		return value, fmt.Sprintf("%s in %s", fileKey, o.file), true, nil
+	}
+	return "", "", false, nil
+}
+
+// formatFileValue formats the decoded value of the configuration file in the
+// form of the environment variables, e.g. lists are comma-separated.
+func formatFileValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i, elem := range v {
+			s, err := formatFileValue(elem)
+			if err != nil {
+				return "", err
+			}
+			elems[i] = s
+		}
+		return strings.Join(elems, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("unexpected table")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue parses the string into the value. Integers can be in any base with
+// the prefix, e.g. "0660", and slices are comma-separated.
+func setValue(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		elems := strings.Split(s, ",")
+		slice := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := setValue(slice.Index(i), strings.TrimSpace(elem)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return errors.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// snakeCase converts the field name to snake case, keeping the acronyms and
+// the numbers together, e.g. TLSCertFile to tls_cert_file, Argon2Memory to
+// argon2_memory and H2C to h2c.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// maskedValue is printed in place of the value of secret fields.
+const maskedValue = "********"
+
+// Print writes the current configuration to w in the form of environment
+// variables. Values of fields tagged with `secret:"true"` are masked.
+func Print(w io.Writer) error {
+	for _, section := range sections {
+		if _, err := fmt.Fprintf(w, "# %s\n", section.name); err != nil {
+			return err
+		}
+
+		v := reflect.ValueOf(section.ptr).Elem()
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := field.Tag.Get("env")
+			if key == "" {
+				continue
+			}
+
+			value := formatValue(v.Field(i))
+			if field.Tag.Get("secret") == "true" && value != "" {
+				value = maskedValue
+			}
+			if _, err := fmt.Fprintf(w, "%s=%s\n", key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatValue formats the value in the form that it is parsed from, e.g.
+// slices are comma-separated and file modes are octal.
+func formatValue(v reflect.Value) string {
+	if mode, ok := v.Interface().(os.FileMode); ok {
+		return fmt.Sprintf("%#o", uint32(mode))
+	}
+	if v.Kind() != reflect.Slice {
+		return fmt.Sprint(v.Interface())
+	}
+	elems := make([]string, v.Len())
+	for i := range elems {
+		elems[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(elems, ",")
+}
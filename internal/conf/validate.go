@@ -0,0 +1,51 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError reports all the problems of the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e.Problems, "; "))
+}
+
+// validate checks the relations between the fields that can't be expressed
+// by the field tags.
+func validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(len(App.TrustedProxies) == 0 || App.IpHeader != "", "APP_TRUSTED_PROXIES requires IP_HEADER")
+	check(App.IpHeader == "" || len(App.TrustedProxies) > 0 || Server.UnixSocket != "", "IP_HEADER requires APP_TRUSTED_PROXIES, e.g. 127.0.0.1/32 for a local proxy")
+	check(Server.Port >= 0 && Server.Port <= 65535, "SERVER_PORT must be between 0 and 65535, got %d", Server.Port)
+	check((Server.TLSCertFile == "") == (Server.TLSKeyFile == ""), "SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together")
+	check(Server.TLSClientCAFile == "" || Server.TLSCertFile != "", "SERVER_TLS_CLIENT_CA_FILE requires SERVER_TLS_CERT_FILE")
+	check(Server.MaxHeaderBytes > 0, "SERVER_MAX_HEADER_BYTES must be positive")
+	check(App.MaxBodySize > 0, "APP_MAX_BODY_SIZE must be positive")
+	check(!RateLimit.Enabled || RateLimit.Backend != "redis" || Redis.Address != "", "RATE_LIMIT_BACKEND redis requires REDIS_ADDRESS")
+	check(Tracing.SamplerRatio >= 0 && Tracing.SamplerRatio <= 1, "TRACING_SAMPLER_RATIO must be between 0 and 1, got %v", Tracing.SamplerRatio)
+	check(strings.HasPrefix(Metrics.Path, "/"), "METRICS_PATH must start with /, got %q", Metrics.Path)
+	check(!Admin.Enabled || Admin.Address != "", "ADMIN_ENABLED requires ADMIN_ADDRESS")
+	check(!Metrics.Enabled || Admin.Enabled || Metrics.Public, "METRICS_ENABLED requires ADMIN_ENABLED, or METRICS_PUBLIC to serve the metrics by the main server")
+	check(Password.Argon2Time >= 1, "PASSWORD_ARGON2_TIME must be positive")
+	check(Password.Argon2Threads >= 1, "PASSWORD_ARGON2_THREADS must be positive")
+	check(Password.Argon2Memory >= 8*uint32(Password.Argon2Threads), "PASSWORD_ARGON2_MEMORY must be at least 8 KiB per thread, got %d", Password.Argon2Memory)
+	check(Password.BcryptCost >= 4 && Password.BcryptCost <= 31, "PASSWORD_BCRYPT_COST must be between 4 and 31, got %d", Password.BcryptCost)
+	check(Password.ScryptLogN >= 1 && Password.ScryptLogN <= 30, "PASSWORD_SCRYPT_LOG_N must be between 1 and 30, got %d", Password.ScryptLogN)
+	check(Password.ScryptR >= 1 && Password.ScryptP >= 1 && Password.ScryptR*Password.ScryptP < 1<<30, "PASSWORD_SCRYPT_R and PASSWORD_SCRYPT_P must be positive and their product less than 2^30")
+	check(Lockout.Duration <= Lockout.MaxDuration, "LOCKOUT_DURATION must not exceed LOCKOUT_MAX_DURATION")
+	return problems
+}
@@ -8,18 +8,48 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
 
 	"github.com/flamego/flamego"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 
 	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/db"
 	"github.com/wuhan005/go-template/internal/dbutil"
+	"github.com/wuhan005/go-template/internal/errs"
+	"github.com/wuhan005/go-template/internal/i18n"
+	"github.com/wuhan005/go-template/internal/logging"
 )
 
 // Context represents context of a request.
 type Context struct {
 	flamego.Context
+
+	User     *db.User
+	IsLogged bool
+	// Locale is the locale of the messages resolved from the Accept-Language header.
+	Locale *i18n.Locale
+}
+
+// locale returns the locale of the request, which is resolved on demand if the
+// context is not initialized by Contexter.
+func (c *Context) locale() *i18n.Locale {
+	if c.Locale == nil {
+		c.Locale = i18n.Match(c.Request().Header.Get("Accept-Language"))
+	}
+	return c.Locale
+}
+
+// Tr returns the localized message of the key formatted with the args.
+func (c *Context) Tr(key string, args ...interface{}) string {
+	return c.locale().Tr(key, args...)
 }
 
 // Success sends a successful response with optional data.
@@ -46,20 +76,72 @@ func (c *Context) Success(data ...interface{}) error {
 
 // ServerError sends a 500 Internal Server Error response.
 func (c *Context) ServerError() error {
-	return c.Error(http.StatusInternalServerError, "Internal server error")
+	return c.writeError(errs.Internal)
 }
 
 // Error sends an error response with a specific status code and message.
 func (c *Context) Error(statusCode int, message string, v ...interface{}) error {
-	c.ResponseWriter().Header().Set("Content-Type", "application/json; charset=utf-8")
-	c.ResponseWriter().WriteHeader(statusCode)
+	return c.writeError(&errs.Error{
+		Status:  statusCode,
+		Message: fmt.Sprintf(message, v...),
+	})
+}
 
-	err := json.NewEncoder(c.ResponseWriter()).Encode(
-		map[string]interface{}{
-			"error": statusCode,
-			"msg":   fmt.Sprintf(message, v...),
-		},
-	)
+// problemContentType is the media type of RFC 7807 problem details.
+const problemContentType = "application/problem+json"
+
+// wantsProblem reports whether the error response should be written as
+// problem details, either configured or accepted by the client.
+func (c *Context) wantsProblem() bool {
+	return conf.App.ProblemDetails || strings.Contains(c.Request().Header.Get("Accept"), problemContentType)
+}
+
+// writeError writes the error response. The message is localized if there is
+// a message of the code, and the code and the invalid fields are omitted in the
+// legacy format if they are empty.
+func (c *Context) writeError(e *errs.Error) error {
+	message := e.Message
+	if key := "error." + e.Code; e.Code != "" && c.locale().Has(key) {
+		message = c.Tr(key, e.Args...)
+	}
+
+	var contentType string
+	var body map[string]interface{}
+	if c.wantsProblem() {
+		problemType := "about:blank"
+		if e.Code != "" {
+			problemType = "urn:problem-type:" + e.Code
+		}
+		contentType = problemContentType
+		body = map[string]interface{}{
+			"type":       problemType,
+			"title":      http.StatusText(e.Status),
+			"status":     e.Status,
+			"detail":     message,
+			"instance":   c.Request().URL.Path,
+			"request_id": c.RequestID(),
+		}
+		if e.Code != "" {
+			body["code"] = e.Code
+		}
+		if len(e.Fields) > 0 {
+			body["errors"] = e.Fields
+		}
+	} else {
+		contentType = "application/json; charset=utf-8"
+		body = map[string]interface{}{
+			"error":      e.Status,
+			"msg":        message,
+			"request_id": c.RequestID(),
+		}
+		if e.Code != "" {
+			body["code"] = e.Code
+		}
+	}
+
+	c.ResponseWriter().Header().Set("Content-Type", contentType)
+	c.ResponseWriter().WriteHeader(e.Status)
+	err := json.NewEncoder(c.ResponseWriter()).Encode(body)
 	if err != nil {
 		logrus.WithContext(c.Request().Context()).WithError(err).Error("Failed to encode")
 		return c.ServerError()
@@ -67,28 +149,122 @@ func (c *Context) Error(statusCode int, message string, v ...interface{}) error
 	return nil
 }
 
+// RequestID returns the ID of the request.
+func (c *Context) RequestID() string {
+	return RequestIDFromContext(c.Request().Context())
+}
+
 // Status sets the HTTP status code for the response.
 func (c *Context) Status(statusCode int) {
 	c.ResponseWriter().WriteHeader(statusCode)
 }
 
-// IP retrieves the client's IP address from the request.
-func (c *Context) IP() string {
-	ipHeader := conf.App.IpHeader
-	if ipHeader != "" {
-		return c.Request().Header.Get(ipHeader)
+// IP returns the IP address of the client, see ClientIP for how it is resolved.
+func (c *Context) IP() netip.Addr {
+	return ClientIP(c.Request().Request, conf.App.TrustedProxies)
+}
+
+var (
+	// ErrUnauthorized is returned when the user is not signed in.
+	ErrUnauthorized = errs.Unauthorized("unauthorized", "Unauthorized")
+	// ErrPermissionDenied is returned when the user lacks the permission.
+	ErrPermissionDenied = errs.Forbidden("permission_denied", "Permission denied")
+)
+
+// SignInRequired is a handler that rejects the request if the user is not signed in.
+func SignInRequired(c Context) error {
+	if !c.IsLogged {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// requirePermission checks if the signed-in user has the permission.
+func (c *Context) requirePermission(permission db.Permission) error {
+	ok, err := db.Roles.HasPermission(c.Request().Context(), c.User.ID, permission)
+	if err != nil {
+		return errors.Wrap(err, "check permission")
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// Require returns a handler that rejects the request unless the signed-in user
+// has the given permission.
+func Require(permission db.Permission) flamego.Handler {
+	return func(c Context) error {
+		if !c.IsLogged {
+			return ErrUnauthorized
+		}
+		return c.requirePermission(permission)
+	}
+}
+
+// RequireSelfOr returns a handler that rejects the request unless the
+// signed-in user is the user loaded by a preceding handler, or has the given
+// permission.
+func RequireSelfOr(permission db.Permission) flamego.Handler {
+	return func(c Context, user *db.User) error {
+		if !c.IsLogged {
+			return ErrUnauthorized
+		}
+		if c.User.ID == user.ID {
+			return nil
+		}
+		return c.requirePermission(permission)
 	}
-	return c.Request().RemoteAddr
+}
+
+// authenticatedUser returns the user of the session that the request carries.
+func authenticatedUser(c Context) (*db.User, bool) {
+	token := c.Cookie(conf.Session.CookieName)
+	if token == "" {
+		return nil, false
+	}
+
+	ctx := c.Request().Context()
+	session, err := db.Sessions.GetByToken(ctx, token)
+	if err != nil {
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			logrus.WithContext(ctx).WithError(err).Error("Failed to get session")
+		}
+		return nil, false
+	}
+
+	user, err := db.Users.GetByID(ctx, strconv.FormatUint(uint64(session.UserID), 10))
+	if err != nil {
+		if !errors.Is(err, db.ErrUserNotFound) {
+			logrus.WithContext(ctx).WithError(err).Error("Failed to get session user")
+		}
+		return nil, false
+	}
+	return user, true
 }
 
 // Contexter initializes a classic context for a request.
-func Contexter(gormDB *gorm.DB) flamego.Handler {
+// The Redis client is optional and only mapped when it is not nil.
+func Contexter(gormDB *gorm.DB, redisClient *goredis.Client) flamego.Handler {
 	return func(ctx flamego.Context) {
 		c := Context{
 			Context: ctx,
 		}
+		c.User, c.IsLogged = authenticatedUser(c)
+		c.Locale = i18n.Match(c.Request().Header.Get("Accept-Language"))
+
+		if ip := c.IP(); ip.IsValid() {
+			trace.SpanFromContext(c.Request().Context()).SetAttributes(attribute.String("client.address", ip.String()))
+		}
 
 		c.MapTo(gormDB, (*dbutil.Transactor)(nil))
+		if redisClient != nil {
+			c.Map(redisClient)
+		}
+		if c.IsLogged {
+			c.Map(c.User)
+			c.Request().Request = c.Request().WithContext(logging.WithField(c.Request().Context(), "user_id", c.User.ID))
+		}
 		c.Map(c)
 	}
 }
@@ -0,0 +1,221 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flamego/flamego"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/db"
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+func TestContexter_Redis(t *testing.T) {
+	conf.Session.CookieName = "session"
+
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	newFlame := func(redisClient *goredis.Client) *flamego.Flame {
+		f := flamego.New()
+		f.Map(ReturnHandler())
+		f.Use(Contexter(&gorm.DB{}, redisClient))
+		return f
+	}
+
+	t.Run("mapped", func(t *testing.T) {
+		f := newFlame(client)
+		f.Get("/", func(c Context, redisClient *goredis.Client) string {
+			return redisClient.Ping(c.Request().Context()).Val()
+		})
+
+		resp := httptest.NewRecorder()
+		f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "PONG", resp.Body.String())
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		f := newFlame(nil)
+		f.Get("/", func(c flamego.Context) string {
+			if c.Value(reflect.TypeOf((*goredis.Client)(nil))).IsValid() {
+				return "mapped"
+			}
+			return "not mapped"
+		})
+
+		resp := httptest.NewRecorder()
+		f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "not mapped", resp.Body.String())
+	})
+}
+
+// mockRolesStore grants the permissions of each user.
+type mockRolesStore struct {
+	db.RolesStore
+	permissions map[uint][]db.Permission
+}
+
+func (s *mockRolesStore) HasPermission(_ context.Context, userID uint, permission db.Permission) (bool, error) {
+	for _, p := range s.permissions[userID] {
+		if p == permission || p == db.PermissionAll {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newUser returns a user of the ID.
+func newUser(id uint) *db.User {
+	user := &db.User{}
+	user.ID = id
+	return user
+}
+
+func TestRequire(t *testing.T) {
+	roles := db.Roles
+	t.Cleanup(func() { db.Roles = roles })
+	db.Roles = &mockRolesStore{
+		permissions: map[uint][]db.Permission{
+			1: {db.PermissionAll},
+			2: {db.PermissionUsersRead},
+		},
+	}
+
+	// signIn maps the context of the signed-in user, instead of a session.
+	signIn := func(user *db.User) flamego.Handler {
+		return func(ctx flamego.Context) {
+			ctx.Map(Context{Context: ctx, User: user, IsLogged: user != nil})
+		}
+	}
+	// loadUser maps the user of the path parameter, like the user routes do.
+	loadUser := func(c flamego.Context) {
+		c.Map(newUser(uint(c.ParamInt("id"))))
+	}
+	ok := func() string { return "ok" }
+
+	tests := []struct {
+		name     string
+		user     *db.User
+		path     string
+		wantCode int
+	}{
+		{name: "signed out", path: "/users", wantCode: http.StatusUnauthorized},
+		{name: "admin wildcard", user: newUser(1), path: "/users", wantCode: http.StatusOK},
+		{name: "granted", user: newUser(2), path: "/users", wantCode: http.StatusOK},
+		{name: "missing permission", user: newUser(3), path: "/users", wantCode: http.StatusForbidden},
+
+		{name: "self signed out", path: "/users/3", wantCode: http.StatusUnauthorized},
+		{name: "self", user: newUser(3), path: "/users/3", wantCode: http.StatusOK},
+		{name: "other user", user: newUser(3), path: "/users/2", wantCode: http.StatusForbidden},
+		{name: "other user with permission", user: newUser(2), path: "/users/3", wantCode: http.StatusForbidden},
+		{name: "other user as admin", user: newUser(1), path: "/users/3", wantCode: http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := flamego.New()
+			f.Map(ReturnHandler())
+			f.Use(signIn(tc.user))
+			f.Get("/users", Require(db.PermissionUsersRead), ok)
+			f.Get("/users/{id}", loadUser, RequireSelfOr(db.PermissionUsersUpdate), ok)
+
+			resp := httptest.NewRecorder()
+			f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantCode == http.StatusForbidden {
+				assert.Contains(t, resp.Body.String(), `"code":"permission_denied"`)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	appConf := conf.App
+	t.Cleanup(func() { conf.App = appConf })
+
+	invalid := errs.Invalid("invalid_form", "Email is required").WithFields([]errs.FieldError{
+		{Pointer: "/email", Rule: "required", Message: "Email is required"},
+	})
+	f := flamego.New()
+	f.Map(ReturnHandler())
+	f.Use(RequestID())
+	f.Use(Contexter(&gorm.DB{}, nil))
+	f.Post("/users", func() error { return invalid })
+	f.Get("/users/1", func() error { return db.ErrUserNotFound })
+
+	serve := func(t *testing.T, method, target string, header http.Header) (*httptest.ResponseRecorder, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, nil)
+		req.Header = header
+		req.Header.Set(RequestIDHeader, "req-1234")
+		resp := httptest.NewRecorder()
+		f.ServeHTTP(resp, req)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body), resp.Body.String())
+		return resp, body
+	}
+
+	t.Run("problem details", func(t *testing.T) {
+		conf.App.ProblemDetails = true
+		resp, body := serve(t, http.MethodPost, "/users", http.Header{"Accept-Language": {"en"}})
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+		assert.Equal(t, map[string]interface{}{
+			"type":       "urn:problem-type:invalid_form",
+			"title":      "Bad Request",
+			"status":     float64(http.StatusBadRequest),
+			"detail":     "Email is required",
+			"instance":   "/users",
+			"request_id": "req-1234",
+			"code":       "invalid_form",
+			"errors": []interface{}{
+				map[string]interface{}{"pointer": "/email", "rule": "required", "message": "Email is required"},
+			},
+		}, body)
+	})
+
+	t.Run("accepted by the client", func(t *testing.T) {
+		conf.App.ProblemDetails = false
+		resp, body := serve(t, http.MethodGet, "/users/1", http.Header{
+			"Accept":          {"application/problem+json, application/json;q=0.9"},
+			"Accept-Language": {"zh-CN"},
+		})
+		require.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+		assert.Equal(t, "urn:problem-type:user_not_found", body["type"])
+		assert.Equal(t, "Not Found", body["title"])
+		// The detail is localized.
+		assert.Equal(t, "用户不存在", body["detail"])
+		assert.NotContains(t, body, "errors")
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		conf.App.ProblemDetails = false
+		resp, body := serve(t, http.MethodGet, "/users/1", http.Header{"Accept-Language": {"en"}})
+		require.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+		assert.Equal(t, map[string]interface{}{
+			"error":      float64(http.StatusNotFound),
+			"msg":        "User does not exist",
+			"request_id": "req-1234",
+			"code":       "user_not_found",
+		}, body)
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// unixPeerAddr is the address of the peers of the Unix sockets, which don't
+// have IP addresses.
+var unixPeerAddr = netip.AddrFrom4([4]byte{127, 0, 0, 1})
+
+// ClientIP resolves the IP address of the client. The forwarding header is
+// only trusted if the request comes from one of the trusted proxies, and is
+// parsed from right to left until the first hop that is not a trusted proxy,
+// which is taken as the client. The peers of the Unix sockets are always
+// trusted, as they are the local proxies, and are taken as the loopback
+// address if the header is absent.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	remote := parseAddr(r.RemoteAddr)
+	if isUnixSocket(r) {
+		remote = unixPeerAddr
+	} else if !remote.IsValid() || !isTrusted(remote, trustedProxies) {
+		return remote
+	}
+
+	hops := forwardedHops(r.Header)
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseAddr(hops[i])
+		if !hop.IsValid() {
+			// Stop at the obfuscated or malformed hop, as the hops before it
+			// can't be verified.
+			break
+		}
+		client = hop
+		if !isTrusted(hop, trustedProxies) {
+			break
+		}
+	}
+	return client
+}
+
+// forwardedHops returns the addresses of the hops in the forwarding header
+// configured by IP_HEADER, from the client to the nearest proxy. No header is
+// trusted if it is not configured, as the proxies usually pass the headers
+// they don't set through untouched, which can then be forged by the clients.
+func forwardedHops(header http.Header) []string {
+	switch {
+	case conf.App.IpHeader == "":
+		return nil
+	case strings.EqualFold(conf.App.IpHeader, "Forwarded"):
+		return parseForwarded(header.Values("Forwarded"))
+	default:
+		return splitList(header.Values(conf.App.IpHeader))
+	}
+}
+
+// splitList splits the comma-separated values of the header, which may be
+// sent in multiple lines.
+func splitList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			list = append(list, strings.TrimSpace(item))
+		}
+	}
+	return list
+}
+
+// parseForwarded returns the "for" parameters of the Forwarded header defined
+// in RFC 7239, e.g. `for=192.0.2.60;proto=http, for="[2001:db8::1]:4711"`.
+func parseForwarded(values []string) []string {
+	var hops []string
+	for _, element := range splitList(values) {
+		var hop string
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hop = strings.Trim(value, `"`)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// parseAddr parses the IP address with an optional port, e.g. "192.0.2.60",
+// "192.0.2.60:8080", "[2001:db8::1]:4711" and "2001:db8::1". The IPv4-mapped
+// IPv6 addresses are unmapped.
+func parseAddr(s string) netip.Addr {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap().WithZone("")
+}
+
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnixSocket reports whether the request is accepted on a Unix socket.
+func isUnixSocket(r *http.Request) bool {
+	addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	return ok && addr.Network() == "unix"
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	gocontext "context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8:ffff::/48"),
+	}
+
+	tests := []struct {
+		name       string
+		ipHeader   string
+		remoteAddr string
+		unix       bool
+		headers    map[string][]string
+		want       string
+	}{
+		{
+			name:       "no header configured",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9"}},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "untrusted remote",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "198.51.100.7:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9"}},
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "trusted remote without header",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "spoofed left-most entry",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1, 203.0.113.9"}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "chain of trusted proxies",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1, 203.0.113.9, 10.0.0.2"}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "multiple header lines",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1", "203.0.113.9, 10.0.0.2"}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "malformed hop",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9, not-an-ip"}},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "other header is ignored",
+			ipHeader:   "X-Real-IP",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Real-Ip":       {"203.0.113.9"},
+				"X-Forwarded-For": {"1.1.1.1"},
+				"Forwarded":       {"for=1.1.1.1"},
+			},
+			want: "203.0.113.9",
+		},
+		{
+			name:       "Forwarded",
+			ipHeader:   "Forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {"for=1.1.1.1, for=203.0.113.9;proto=https;by=10.0.0.1"}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "Forwarded quoted IPv6 with port",
+			ipHeader:   "Forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {`for="[2001:db8:cafe::17]:4711"`}},
+			want:       "2001:db8:cafe::17",
+		},
+		{
+			name:       "Forwarded case-insensitive parameter",
+			ipHeader:   "forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {`For="203.0.113.9"`}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "Forwarded unknown",
+			ipHeader:   "Forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {"for=203.0.113.9, for=unknown"}},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "Forwarded obfuscated",
+			ipHeader:   "Forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {"for=203.0.113.9, for=_hidden"}},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "IPv6 trusted proxy",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "[2001:db8:ffff::1]:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"2001:db8::1"}},
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "IPv4-mapped remote",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "[::ffff:10.0.0.1]:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9"}},
+			want:       "203.0.113.9",
+		},
+		{
+			name:       "Unix socket without header configured",
+			remoteAddr: "@",
+			unix:       true,
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9"}},
+			want:       "127.0.0.1",
+		},
+		{
+			name:       "Unix socket without header",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "@",
+			unix:       true,
+			want:       "127.0.0.1",
+		},
+		{
+			name:       "Unix socket",
+			ipHeader:   "X-Forwarded-For",
+			remoteAddr: "@",
+			unix:       true,
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1, 203.0.113.9"}},
+			want:       "203.0.113.9",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ipHeader := conf.App.IpHeader
+			conf.App.IpHeader = tc.ipHeader
+			t.Cleanup(func() { conf.App.IpHeader = ipHeader })
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for key, values := range tc.headers {
+				r.Header[key] = values
+			}
+			if tc.unix {
+				localAddr := &net.UnixAddr{Name: "/run/go-template.sock", Net: "unix"}
+				r = r.WithContext(gocontext.WithValue(r.Context(), http.LocalAddrContextKey, localAddr))
+			}
+
+			assert.Equal(t, netip.MustParseAddr(tc.want), ClientIP(r, trustedProxies))
+		})
+	}
+}
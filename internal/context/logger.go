@@ -0,0 +1,35 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger returns a handler that writes a structured access log for every
+// request once it is served. The route is the matched route pattern to keep
+// the logs easy to aggregate, and is empty if no route is matched.
+func Logger() flamego.Handler {
+	return func(c Context) {
+		start := time.Now()
+
+		c.Next()
+
+		req := c.Request()
+		logrus.WithContext(req.Context()).WithFields(logrus.Fields{
+			"method":     req.Method,
+			"route":      c.Param("route"),
+			"path":       req.URL.Path,
+			"status":     c.ResponseWriter().Status(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":      c.ResponseWriter().Size(),
+			"client_ip":  c.IP(),
+			"user_agent": req.UserAgent(),
+		}).Info("Request completed")
+	}
+}
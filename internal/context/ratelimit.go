@@ -0,0 +1,87 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/flamego/flamego"
+	"github.com/sirupsen/logrus"
+
+	"github.com/wuhan005/go-template/internal/errs"
+	"github.com/wuhan005/go-template/internal/ratelimit"
+)
+
+// ErrRateLimited is returned when the client has sent too many requests.
+var ErrRateLimited = errs.RateLimited("rate_limited", "Too many requests")
+
+// RateLimitKeyFunc returns the key to count the requests of the client by.
+type RateLimitKeyFunc func(c Context) string
+
+// RateLimitByIP counts the requests by the client IP.
+func RateLimitByIP(c Context) string {
+	return "ip:" + c.IP().String()
+}
+
+// RateLimitByUser counts the requests by the signed-in user, or the client IP
+// if the user is not signed in.
+func RateLimitByUser(c Context) string {
+	if c.IsLogged {
+		return "user:" + strconv.FormatUint(uint64(c.User.ID), 10)
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByAPIKey returns a RateLimitKeyFunc that counts the requests by the
+// API key in the header if it is valid, or the client IP otherwise, so that
+// the clients can't get new buckets by making up keys. The key is hashed to
+// not be stored in the clear by the limiter.
+func RateLimitByAPIKey(header string, valid func(c Context, key string) bool) RateLimitKeyFunc {
+	return func(c Context) string {
+		key := c.Request().Header.Get(header)
+		if key == "" || !valid(c, key) {
+			return RateLimitByIP(c)
+		}
+		sum := sha256.Sum256([]byte(key))
+		return "key:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// RateLimit returns a handler that rejects the request with 429 if the client
+// exceeds the policy. The name identifies the buckets of the route, and the
+// limiter failures are logged and let the request through.
+func RateLimit(limiter ratelimit.Limiter, name string, policy ratelimit.Policy, keyFunc RateLimitKeyFunc) flamego.Handler {
+	return func(c Context) error {
+		if limiter == nil {
+			return nil
+		}
+
+		ctx := c.Request().Context()
+		result, err := limiter.Allow(ctx, name+":"+keyFunc(c), policy)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Error("Failed to check rate limit")
+			return nil
+		}
+
+		header := c.ResponseWriter().Header()
+		header.Set("RateLimit-Policy", policy.String())
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+		if !result.Allowed {
+			header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			return ErrRateLimited
+		}
+		return nil
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	gocontext "context"
+
+	"github.com/flamego/flamego"
+	"github.com/rs/xid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wuhan005/go-template/internal/logging"
+)
+
+// RequestIDHeader is the header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximum length of the request ID accepted from clients.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID stored in the context, or empty
+// if there is none.
+func RequestIDFromContext(ctx gocontext.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether the request ID from clients is safe to be
+// echoed and logged, i.e. not too long and only contains printable ASCII
+// characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestID returns a handler that takes the request ID from the request
+// header, or generates one if it is absent or invalid. The request ID is
+// stored in the request context, echoed in the response header, and attached
+// to the logs and the current span.
+func RequestID() flamego.Handler {
+	return func(c flamego.Context) {
+		id := c.Request().Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = xid.New().String()
+		}
+
+		ctx := gocontext.WithValue(c.Request().Context(), requestIDKey{}, id)
+		ctx = logging.WithField(ctx, "request_id", id)
+		c.Request().Request = c.Request().WithContext(ctx)
+
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", id))
+		c.ResponseWriter().Header().Set(RequestIDHeader, id)
+	}
+}
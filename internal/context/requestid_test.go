@@ -0,0 +1,80 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+func TestRequestID(t *testing.T) {
+	f := flamego.New()
+	f.Map(ReturnHandler())
+	f.Use(RequestID())
+	f.Use(Contexter(&gorm.DB{}, nil))
+	f.Get("/", func(c Context) string { return c.RequestID() })
+	f.Get("/error", func() error { return errs.NotFound("not_found", "Not found") })
+
+	tests := []struct {
+		name     string
+		incoming string
+		want     string // Empty if a new ID is expected.
+	}{
+		{name: "absent"},
+		{name: "valid", incoming: "req-1234_abc.DEF", want: "req-1234_abc.DEF"},
+		{name: "max length", incoming: strings.Repeat("a", maxRequestIDLength), want: strings.Repeat("a", maxRequestIDLength)},
+		{name: "too long", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "space", incoming: "req 1234"},
+		{name: "control character", incoming: "req\x7f1234"},
+		{name: "non-ASCII", incoming: "请求"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.incoming != "" {
+				req.Header.Set(RequestIDHeader, tc.incoming)
+			}
+			resp := httptest.NewRecorder()
+			f.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			got := resp.Header().Get(RequestIDHeader)
+			if tc.want != "" {
+				assert.Equal(t, tc.want, got)
+			} else {
+				_, err := xid.FromString(got)
+				assert.NoError(t, err, "generated ID %q", got)
+			}
+			// The handlers see the same ID as the response header.
+			assert.Equal(t, got, resp.Body.String())
+		})
+	}
+
+	t.Run("error response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/error", nil)
+		req.Header.Set(RequestIDHeader, "req-1234")
+		resp := httptest.NewRecorder()
+		f.ServeHTTP(resp, req)
+		require.Equal(t, http.StatusNotFound, resp.Code)
+
+		var body struct {
+			RequestID string `json:"request_id"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, "req-1234", body.RequestID)
+		assert.Equal(t, "req-1234", resp.Header().Get(RequestIDHeader))
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"reflect"
+
+	"github.com/flamego/flamego"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+// ReturnHandler returns the handler of the values returned by the route
+// handlers. A non-nil error is written as the error response of its
+// *errs.Error, and any other error is written as an internal server error,
+// which is the only case to be logged. A string or []byte is written as is.
+func ReturnHandler() flamego.ReturnHandler {
+	return func(ctx flamego.Context, vals []reflect.Value) {
+		if len(vals) == 0 {
+			return
+		}
+		// Use the context initialized by Contexter if any, e.g. for the locale.
+		c := Context{Context: ctx}
+		if v := ctx.Value(reflect.TypeOf(c)); v.IsValid() {
+			c = v.Interface().(Context)
+		}
+
+		val := vals[len(vals)-1]
+		if err, ok := val.Interface().(error); ok {
+			var e *errs.Error
+			if !errors.As(err, &e) {
+				e = errs.Internal
+			}
+			if e.Status >= 500 {
+				logrus.WithContext(c.Request().Context()).WithError(err).Error("Failed to handle request")
+			}
+			if c.ResponseWriter().Written() {
+				return
+			}
+			_ = c.writeError(e)
+			return
+		}
+
+		switch v := val.Interface().(type) {
+		case string:
+			_, _ = c.ResponseWriter().Write([]byte(v))
+		case []byte:
+			_, _ = c.ResponseWriter().Write(v)
+		}
+	}
+}
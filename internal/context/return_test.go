@@ -0,0 +1,117 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+func TestReturnHandler(t *testing.T) {
+	hook := test.NewGlobal()
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantBody string
+		wantLog  bool
+	}{
+		{
+			name:     "client error",
+			err:      errs.NotFound("user_not_found", "User does not exist"),
+			wantCode: http.StatusNotFound,
+			wantBody: `"code":"user_not_found"`,
+		},
+		{
+			name:     "wrapped client error",
+			err:      errors.Wrap(errs.Invalid("invalid_form", "Name is required"), "bind"),
+			wantCode: http.StatusBadRequest,
+			wantBody: `"code":"invalid_form"`,
+		},
+		{
+			name:     "server error",
+			err:      errs.New(http.StatusServiceUnavailable, "unavailable", "Service unavailable"),
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `"code":"unavailable"`,
+			wantLog:  true,
+		},
+		{
+			name:     "unexpected error",
+			err:      errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: `"code":"internal"`,
+			wantLog:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hook.Reset()
+			f := flamego.New()
+			f.Map(ReturnHandler())
+			f.Use(Contexter(&gorm.DB{}, nil))
+			f.Get("/", func() error { return tc.err })
+
+			resp := httptest.NewRecorder()
+			f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tc.wantBody)
+			// The details of the unexpected errors are only logged.
+			assert.NotContains(t, resp.Body.String(), "10.0.0.1")
+
+			if tc.wantLog {
+				entry := hook.LastEntry()
+				require.NotNil(t, entry)
+				assert.Equal(t, logrus.ErrorLevel, entry.Level)
+				assert.Equal(t, tc.err, entry.Data[logrus.ErrorKey])
+			} else {
+				assert.Empty(t, hook.AllEntries())
+			}
+		})
+	}
+
+	t.Run("written response", func(t *testing.T) {
+		hook.Reset()
+		f := flamego.New()
+		f.Map(ReturnHandler())
+		f.Get("/", func(c flamego.Context) error {
+			c.ResponseWriter().WriteHeader(http.StatusAccepted)
+			return errors.New("failed after writing")
+		})
+
+		resp := httptest.NewRecorder()
+		f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Empty(t, resp.Body.String())
+		// The error is still logged although the response can't be changed.
+		assert.Len(t, hook.AllEntries(), 1)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		f := flamego.New()
+		f.Map(ReturnHandler())
+		f.Get("/string", func() string { return "hello" })
+		f.Get("/bytes", func() []byte { return []byte("world") })
+		f.Get("/nil", func() error { return nil })
+
+		for path, want := range map[string]string{"/string": "hello", "/bytes": "world", "/nil": ""} {
+			resp := httptest.NewRecorder()
+			f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, http.StatusOK, resp.Code, path)
+			assert.Equal(t, want, resp.Body.String(), path)
+		}
+	})
+}
@@ -7,30 +7,49 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 	"gorm.io/plugin/opentelemetry/tracing"
 
 	"github.com/wuhan005/go-template/internal/conf"
 	"github.com/wuhan005/go-template/internal/dbutil"
+	"github.com/wuhan005/go-template/internal/migrate"
 )
 
-var tables = []interface{}{
-	&User{},
-}
-
 var dbInstance *gorm.DB
 
-// Init initializes the database.
+// Init initializes the database, applies the migrations and sets up the stores.
 func Init() (*gorm.DB, error) {
+	db, err := Open()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateDatabase(db); err != nil {
+		return nil, errors.Wrap(err, "migrate")
+	}
+
+	SetDatabaseStore(db)
+
+	if conf.App.SecretKey != "" {
+		dbutil.CursorSecret = []byte(conf.App.SecretKey)
+	} else {
+		logrus.Warn("APP_SECRET_KEY is not set, pagination cursors are signed with a random key and are invalidated on restart and between instances")
+	}
+
+	dbInstance = db
+
+	return db, nil
+}
+
+// Open opens a connection to the database without migrating it.
+func Open() (*gorm.DB, error) {
 	dsn := conf.Postgres.DSN
 	dsnURL, err := pgx.ParseConfig(dsn)
 	if err != nil {
@@ -42,15 +61,7 @@ func Init() (*gorm.DB, error) {
 		NowFunc: func() time.Time {
 			return dbutil.Now()
 		},
-		Logger: logger.New(
-			log.New(os.Stdout, "\r\n", log.LstdFlags),
-			logger.Config{
-				SlowThreshold:             3 * time.Second,
-				LogLevel:                  logger.Silent,
-				IgnoreRecordNotFoundError: true,
-				Colorful:                  false,
-			},
-		),
+		Logger: newGormLogger(),
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "open connection")
@@ -64,17 +75,42 @@ func Init() (*gorm.DB, error) {
 	)); err != nil {
 		return nil, errors.Wrap(err, "register otelgorm plugin")
 	}
+	return db, nil
+}
 
-	// Migrate databases.
-	if err := db.AutoMigrate(tables...); err != nil {
-		return nil, errors.Wrap(err, "auto migrate")
+// migrateDatabase applies the pending migrations, or checks that there is none
+// if auto migration is disabled.
+func migrateDatabase(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return errors.Wrap(err, "get db")
 	}
 
-	SetDatabaseStore(db)
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		return errors.Wrap(err, "new migrator")
+	}
 
-	dbInstance = db
+	ctx := context.Background()
+	if !conf.Postgres.AutoMigrate {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return errors.Wrap(err, "get pending migrations")
+		}
+		if len(pending) > 0 {
+			return errors.Errorf("database schema is behind by %d migration(s), apply them before starting the server", len(pending))
+		}
+		return nil
+	}
 
-	return db, nil
+	migrations, err := migrator.Up(ctx)
+	if err != nil {
+		return errors.Wrap(err, "up")
+	}
+	for _, migration := range migrations {
+		logrus.WithField("version", migration.Version).WithField("name", migration.Name).Info("Applied migration")
+	}
+	return nil
 }
 
 // Ping checks the database connection.
@@ -90,7 +126,24 @@ func Ping(ctx context.Context) error {
 	return nil
 }
 
+// CheckSchema checks that all the migrations have been applied.
+func CheckSchema(ctx context.Context) error {
+	sqlDB, err := dbInstance.DB()
+	if err != nil {
+		return fmt.Errorf("get db: %w", err)
+	}
+
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		return fmt.Errorf("new migrator: %w", err)
+	}
+	return migrator.Check(ctx)
+}
+
 // SetDatabaseStore sets the database table store.
 func SetDatabaseStore(db *gorm.DB) {
 	Users = NewUsersStore(db)
+	Sessions = NewSessionsStore(db)
+	Roles = NewRolesStore(db)
+	LoginAttempts = NewLoginAttemptsStore(db)
 }
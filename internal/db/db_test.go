@@ -0,0 +1,45 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/dbtest"
+	"github.com/wuhan005/go-template/internal/password"
+)
+
+// newTestDB creates a migrated database for the test, which is dropped after
+// the test. See dbtest.NewDatabase for the server it is created on.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := dbtest.NewDatabase(t)
+
+	postgresConf := conf.Postgres
+	passwordConf := conf.Password
+	t.Cleanup(func() {
+		conf.Postgres = postgresConf
+		conf.Password = passwordConf
+	})
+	conf.Postgres.DSN = dsn
+	conf.Postgres.AutoMigrate = true
+	// The cheapest hashing keeps the tests fast.
+	conf.Password.Algorithm = password.Bcrypt
+	conf.Password.BcryptCost = bcrypt.MinCost
+
+	db, err := Open()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	require.NoError(t, migrateDatabase(db))
+	return db
+}
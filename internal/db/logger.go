@@ -0,0 +1,110 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/wuhan005/go-template/internal/dbutil"
+)
+
+var (
+	_ logger.Interface  = (*gormLogger)(nil)
+	_ gorm.ParamsFilter = (*gormLogger)(nil)
+)
+
+// gormLogger sends the GORM logs to logrus, so they share the format and the
+// fields injected from the context with the rest of the logs. Failed and slow
+// queries are logged at the error and warn levels, others at the trace level.
+// The expected errors, i.e. record not found and unique violations, are logged
+// at the debug level.
+//
+// The queries are logged with the placeholders instead of the values, which
+// may be password hashes, emails or tokens.
+type gormLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger() *gormLogger {
+	return &gormLogger{
+		level:         logger.Info,
+		slowThreshold: 3 * time.Second,
+	}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		logrus.WithContext(ctx).Infof(msg, data...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		logrus.WithContext(ctx).Warnf(msg, data...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		logrus.WithContext(ctx).Errorf(msg, data...)
+	}
+}
+
+// ParamsFilter drops the values of the query, so that the SQL is explained with
+// the placeholders, e.g. "$1$", which are rewritten back to "$1" by Trace.
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// explainedPlaceholder matches the placeholders left by explaining a query
+// without values, e.g. "$1$".
+var explainedPlaceholder = regexp.MustCompile(`\$(\d+)\$`)
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	entry := func() *logrus.Entry {
+		sql, rows := fc()
+		return logrus.WithContext(ctx).WithFields(logrus.Fields{
+			"sql":        explainedPlaceholder.ReplaceAllString(sql, "$$$1"),
+			"rows":       rows,
+			"elapsed_ms": float64(elapsed.Microseconds()) / 1000,
+		})
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !expectedError(err):
+		entry().WithError(err).Error("Failed to execute query")
+	case err != nil && l.level >= logger.Error:
+		entry().WithError(err).Debug("Failed to execute query")
+	case elapsed > l.slowThreshold && l.level >= logger.Warn:
+		entry().Warn("Slow query")
+	case l.level >= logger.Info && logrus.IsLevelEnabled(logrus.TraceLevel):
+		entry().Trace("Executed query")
+	}
+}
+
+// expectedError reports whether the error is returned to the callers as part of
+// the normal flow, e.g. ErrEmailTaken, which is not worth an error log.
+func expectedError(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || dbutil.IsUniqueViolation(err, "")
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGormLogger_Trace(t *testing.T) {
+	hook := test.NewGlobal()
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.TraceLevel)
+	t.Cleanup(func() { logrus.SetLevel(level) })
+
+	l := newGormLogger()
+	// Explain the query the same way as GORM does with the filtered values.
+	sql, vars := l.ParamsFilter(context.Background(), `INSERT INTO "users" ("email","password") VALUES ($1,$2)`, "alice@example.com", "$argon2id$secret")
+	explained := postgres.Dialector{}.Explain(sql, vars...)
+	fc := func() (string, int64) { return explained, 0 }
+
+	tests := []struct {
+		name  string
+		err   error
+		level logrus.Level
+	}{
+		{name: "ok", err: nil, level: logrus.TraceLevel},
+		{name: "record not found", err: gorm.ErrRecordNotFound, level: logrus.DebugLevel},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505", ConstraintName: "users_email_unique"}, level: logrus.DebugLevel},
+		{name: "unexpected", err: errors.New("connection reset"), level: logrus.ErrorLevel},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hook.Reset()
+			l.Trace(context.Background(), time.Now(), fc, tc.err)
+
+			entry := hook.LastEntry()
+			require.NotNil(t, entry)
+			assert.Equal(t, tc.level, entry.Level)
+			assert.Equal(t, `INSERT INTO "users" ("email","password") VALUES ($1,$2)`, entry.Data["sql"])
+		})
+	}
+
+	t.Run("silent", func(t *testing.T) {
+		hook.Reset()
+		l.LogMode(logger.Silent).Trace(context.Background(), time.Now(), fc, errors.New("connection reset"))
+		assert.Nil(t, hook.LastEntry())
+	})
+}
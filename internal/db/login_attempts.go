@@ -0,0 +1,146 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/dbutil"
+)
+
+var _ LoginAttemptsStore = (*loginAttempts)(nil)
+
+// LoginAttempts is the default instance of the LoginAttemptsStore.
+var LoginAttempts LoginAttemptsStore
+
+// LoginAttemptsStore is the persistent interface for failed sign-in attempts.
+type LoginAttemptsStore interface {
+	// LockedUntil returns the time until which the key is locked, which is
+	// zero if the key is not locked.
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// RecordFailure records a failed attempt of the key, and locks the key
+	// once the failures reach a multiple of the max attempts of the policy.
+	// It returns the time until which the key is locked.
+	RecordFailure(ctx context.Context, key string, policy LockoutPolicy) (time.Time, error)
+	// Reset forgets the failed attempts of the key and unlocks it.
+	Reset(ctx context.Context, key string) error
+}
+
+// NewLoginAttemptsStore returns a LoginAttemptsStore instance with the given database connection.
+func NewLoginAttemptsStore(db *gorm.DB) LoginAttemptsStore {
+	return &loginAttempts{db}
+}
+
+// EmailLoginKey returns the key of the failed attempts of the email, which is
+// hashed so that the emails of no account are tracked the same way without
+// being stored.
+func EmailLoginKey(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "email:" + hex.EncodeToString(sum[:])
+}
+
+// IPLoginKey returns the key of the failed attempts from the IP address.
+func IPLoginKey(ip netip.Addr) string {
+	return "ip:" + ip.String()
+}
+
+// LockoutPolicy is the policy to lock the keys with too many failed attempts.
+type LockoutPolicy struct {
+	// MaxAttempts is the number of consecutive failures to lock the key, 0
+	// disables the lockout.
+	MaxAttempts int
+	// Duration is the duration of the first lockout, which is doubled for
+	// each subsequent lockout up to MaxDuration.
+	Duration    time.Duration
+	MaxDuration time.Duration
+	// Window is the time after the last failure, or the end of the last
+	// lockout, to forget the failures.
+	Window time.Duration
+}
+
+// lockDuration returns the duration to lock the key with the failures, or
+// zero if the key should not be locked.
+func (p LockoutPolicy) lockDuration(failures int) time.Duration {
+	if p.MaxAttempts <= 0 || failures < p.MaxAttempts || failures%p.MaxAttempts != 0 {
+		return 0
+	}
+
+	duration := p.Duration
+	for i := failures/p.MaxAttempts - 1; i > 0 && duration < p.MaxDuration; i-- {
+		duration *= 2
+	}
+	if p.MaxDuration > 0 && duration > p.MaxDuration {
+		duration = p.MaxDuration
+	}
+	return duration
+}
+
+// LoginAttempt is the failed sign-in attempts of a key.
+type LoginAttempt struct {
+	Key          string `gorm:"primaryKey"`
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+type loginAttempts struct {
+	*gorm.DB
+}
+
+func (db *loginAttempts) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var attempt LoginAttempt
+	if err := db.WithContext(ctx).Where("key = ?", key).First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, errors.Wrap(err, "get")
+	}
+
+	if attempt.LockedUntil == nil || !attempt.LockedUntil.After(dbutil.Now()) {
+		return time.Time{}, nil
+	}
+	return *attempt.LockedUntil, nil
+}
+
+func (db *loginAttempts) RecordFailure(ctx context.Context, key string, policy LockoutPolicy) (time.Time, error) {
+	now := dbutil.Now()
+
+	// Count the failures atomically, starting over if both the last one and the
+	// end of the last lockout are out of the window. Otherwise a lockout as long
+	// as the window would reset the failures, and never escalate.
+	var failures int
+	if err := db.WithContext(ctx).Raw(`
+INSERT INTO login_attempts (key, failures, last_failed_at) VALUES (?, 1, ?)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE WHEN GREATEST(login_attempts.last_failed_at, login_attempts.locked_until) < ? THEN 1 ELSE login_attempts.failures + 1 END,
+    last_failed_at = EXCLUDED.last_failed_at
+RETURNING failures`, key, now, now.Add(-policy.Window)).Scan(&failures).Error; err != nil {
+		return time.Time{}, errors.Wrap(err, "count failure")
+	}
+
+	duration := policy.lockDuration(failures)
+	if duration == 0 {
+		return time.Time{}, nil
+	}
+
+	lockedUntil := now.Add(duration)
+	if err := db.WithContext(ctx).Model(&LoginAttempt{}).Where("key = ?", key).
+		Update("locked_until", lockedUntil).Error; err != nil {
+		return time.Time{}, errors.Wrap(err, "lock")
+	}
+	return lockedUntil, nil
+}
+
+func (db *loginAttempts) Reset(ctx context.Context, key string) error {
+	return db.WithContext(ctx).Where("key = ?", key).Delete(&LoginAttempt{}).Error
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/dbutil"
+)
+
+func TestLockoutPolicy_lockDuration(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 3, Duration: time.Hour, MaxDuration: 4 * time.Hour}
+	for failures, want := range map[int]time.Duration{
+		1:  0,
+		2:  0,
+		3:  time.Hour,
+		4:  0,
+		6:  2 * time.Hour,
+		9:  4 * time.Hour,
+		12: 4 * time.Hour,
+	} {
+		assert.Equal(t, want, policy.lockDuration(failures), "failures %d", failures)
+	}
+	assert.Zero(t, LockoutPolicy{}.lockDuration(10))
+}
+
+// expireLockout moves the failures and the lockout of the key back in time, as
+// if the lockout has ended the given duration ago.
+func expireLockout(t *testing.T, db *gorm.DB, key string, ago time.Duration) {
+	t.Helper()
+	now := dbutil.Now()
+	require.NoError(t, db.Model(&LoginAttempt{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"last_failed_at": now.Add(-ago - time.Hour),
+		"locked_until":   now.Add(-ago),
+	}).Error)
+}
+
+func TestLoginAttempts(t *testing.T) {
+	db := newTestDB(t)
+	store := NewLoginAttemptsStore(db)
+	ctx := context.Background()
+	policy := LockoutPolicy{MaxAttempts: 3, Duration: time.Hour, MaxDuration: 4 * time.Hour, Window: time.Hour}
+
+	// recordFailures records the failures of the key, and returns the duration
+	// of the lockout caused by the last one.
+	recordFailures := func(t *testing.T, key string, n int) time.Duration {
+		t.Helper()
+		var lockedUntil time.Time
+		for i := 0; i < n; i++ {
+			var err error
+			lockedUntil, err = store.RecordFailure(ctx, key, policy)
+			require.NoError(t, err)
+			if i < n-1 {
+				require.Zero(t, lockedUntil, "failure %d", i+1)
+			}
+		}
+		if lockedUntil.IsZero() {
+			return 0
+		}
+		return time.Until(lockedUntil).Round(time.Minute)
+	}
+
+	t.Run("escalation", func(t *testing.T) {
+		key := EmailLoginKey("escalation@example.com")
+
+		assert.Equal(t, time.Hour, recordFailures(t, key, 3))
+		lockedUntil, err := store.LockedUntil(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, lockedUntil.IsZero())
+
+		// The lockout is as long as the window, so the last failure is out of
+		// the window once the lockout ends.
+		for _, want := range []time.Duration{2 * time.Hour, 4 * time.Hour, 4 * time.Hour} {
+			expireLockout(t, db, key, time.Second)
+			lockedUntil, err := store.LockedUntil(ctx, key)
+			require.NoError(t, err)
+			assert.Zero(t, lockedUntil)
+
+			assert.Equal(t, want, recordFailures(t, key, 3))
+		}
+	})
+
+	t.Run("forgotten after the window", func(t *testing.T) {
+		key := EmailLoginKey("window@example.com")
+
+		assert.Equal(t, time.Hour, recordFailures(t, key, 3))
+		expireLockout(t, db, key, policy.Window+time.Second)
+		assert.Equal(t, time.Hour, recordFailures(t, key, 3))
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		key := EmailLoginKey("reset@example.com")
+
+		assert.Equal(t, time.Hour, recordFailures(t, key, 3))
+		require.NoError(t, store.Reset(ctx, key))
+		lockedUntil, err := store.LockedUntil(ctx, key)
+		require.NoError(t, err)
+		assert.Zero(t, lockedUntil)
+
+		// The escalation starts over.
+		assert.Equal(t, time.Hour, recordFailures(t, key, 3))
+	})
+}
+
+func TestUsers_Authenticate_Lockout(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUsersStore(db)
+	attempts := NewLoginAttemptsStore(db)
+	ctx := context.Background()
+
+	lockoutConf := conf.Lockout
+	t.Cleanup(func() { conf.Lockout = lockoutConf })
+	conf.Lockout.MaxAttempts = 3
+	conf.Lockout.IPMaxAttempts = 0
+	conf.Lockout.Duration = time.Hour
+	conf.Lockout.MaxDuration = 4 * time.Hour
+	conf.Lockout.Window = time.Hour
+
+	_, err := users.Create(ctx, CreateUserOptions{Email: "alice@example.com", Password: "correct horse", NickName: "alice"})
+	require.NoError(t, err)
+
+	authenticate := func(email, password string) error {
+		_, err := users.Authenticate(ctx, AuthenticateOptions{Email: email, Password: password})
+		return err
+	}
+
+	t.Run("reset on success", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			require.ErrorIs(t, authenticate("alice@example.com", "wrong"), ErrBadCredentials)
+		}
+		require.NoError(t, authenticate("Alice@example.com", "correct horse"))
+
+		// The failures before the success are forgotten.
+		for i := 0; i < 2; i++ {
+			require.ErrorIs(t, authenticate("alice@example.com", "wrong"), ErrBadCredentials)
+		}
+		require.NoError(t, authenticate("alice@example.com", "correct horse"))
+	})
+
+	t.Run("locked and unlocked", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.ErrorIs(t, authenticate("alice@example.com", "wrong"), ErrBadCredentials)
+		}
+		// The correct password is rejected during the lockout, regardless of
+		// the case of the email.
+		require.ErrorIs(t, authenticate("ALICE@example.com", "correct horse"), ErrLoginLocked)
+
+		require.NoError(t, attempts.Reset(ctx, EmailLoginKey("alice@example.com")))
+		require.NoError(t, authenticate("alice@example.com", "correct horse"))
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.ErrorIs(t, authenticate("nobody@example.com", "wrong"), ErrBadCredentials)
+		}
+		require.ErrorIs(t, authenticate("nobody@example.com", "wrong"), ErrLoginLocked)
+	})
+}
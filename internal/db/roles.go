@@ -0,0 +1,97 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+// Permission is the name of an operation that can be granted to roles.
+type Permission string
+
+const (
+	// PermissionAll grants all the permissions.
+	PermissionAll Permission = "*"
+
+	PermissionUsersRead   Permission = "users.read"
+	PermissionUsersCreate Permission = "users.create"
+	PermissionUsersUpdate Permission = "users.update"
+	PermissionUsersDelete Permission = "users.delete"
+)
+
+// RoleAdmin is the name of the seeded role which has all the permissions.
+const RoleAdmin = "admin"
+
+var _ RolesStore = (*roles)(nil)
+
+// Roles is the default instance of the RolesStore.
+var Roles RolesStore
+
+// RolesStore is the persistent interface for roles and their permissions.
+type RolesStore interface {
+	// GetByName retrieves a role by its name.
+	GetByName(ctx context.Context, name string) (*Role, error)
+	// AssignToUser grants the role with the given name to the user.
+	// It returns ErrRoleNotFound if the role does not exist.
+	AssignToUser(ctx context.Context, userID uint, name string) error
+	// HasPermission checks if any role of the user grants the permission.
+	HasPermission(ctx context.Context, userID uint, permission Permission) (bool, error)
+}
+
+// NewRolesStore returns a RolesStore instance with the given database connection.
+func NewRolesStore(db *gorm.DB) RolesStore {
+	return &roles{db}
+}
+
+type Role struct {
+	ID        uint `gorm:"primarykey"`
+	Name      string
+	CreatedAt time.Time
+}
+
+type roles struct {
+	*gorm.DB
+}
+
+var ErrRoleNotFound = errs.NotFound("role_not_found", "Role does not exist")
+
+func (db *roles) GetByName(ctx context.Context, name string) (*Role, error) {
+	var role Role
+	if err := db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, errors.Wrap(err, "get")
+	}
+	return &role, nil
+}
+
+func (db *roles) AssignToUser(ctx context.Context, userID uint, name string) error {
+	role, err := db.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).
+		Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?) ON CONFLICT DO NOTHING", userID, role.ID).
+		Error
+}
+
+func (db *roles) HasPermission(ctx context.Context, userID uint, permission Permission) (bool, error) {
+	var count int64
+	if err := db.WithContext(ctx).Table("user_roles").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND role_permissions.permission IN ?", userID, []Permission{permission, PermissionAll}).
+		Count(&count).Error; err != nil {
+		return false, errors.Wrap(err, "count")
+	}
+	return count > 0, nil
+}
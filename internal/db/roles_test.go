@@ -0,0 +1,62 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoles_HasPermission(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUsersStore(db)
+	roles := NewRolesStore(db)
+	ctx := context.Background()
+
+	alice, err := users.Create(ctx, CreateUserOptions{Email: "alice@example.com", Password: "correct horse", NickName: "alice"})
+	require.NoError(t, err)
+	bob, err := users.Create(ctx, CreateUserOptions{Email: "bob@example.com", Password: "correct horse", NickName: "bob"})
+	require.NoError(t, err)
+
+	// A role which is only granted to read the users.
+	require.NoError(t, db.Exec("INSERT INTO roles (name) VALUES ('viewer')").Error)
+	require.NoError(t, db.Exec("INSERT INTO role_permissions (role_id, permission) SELECT id, ? FROM roles WHERE name = 'viewer'", PermissionUsersRead).Error)
+
+	t.Run("no role", func(t *testing.T) {
+		ok, err := roles.HasPermission(ctx, alice.ID, PermissionUsersRead)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("missing role", func(t *testing.T) {
+		assert.ErrorIs(t, roles.AssignToUser(ctx, alice.ID, "missing"), ErrRoleNotFound)
+	})
+
+	t.Run("granted permission", func(t *testing.T) {
+		require.NoError(t, roles.AssignToUser(ctx, bob.ID, "viewer"))
+		// Assigning the role again is a no-op.
+		require.NoError(t, roles.AssignToUser(ctx, bob.ID, "viewer"))
+
+		ok, err := roles.HasPermission(ctx, bob.ID, PermissionUsersRead)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = roles.HasPermission(ctx, bob.ID, PermissionUsersDelete)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("seeded admin wildcard", func(t *testing.T) {
+		require.NoError(t, roles.AssignToUser(ctx, alice.ID, RoleAdmin))
+		for _, permission := range []Permission{PermissionUsersRead, PermissionUsersCreate, PermissionUsersUpdate, PermissionUsersDelete, "unknown.permission"} {
+			ok, err := roles.HasPermission(ctx, alice.ID, permission)
+			require.NoError(t, err)
+			assert.True(t, ok, permission)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thanhpk/randstr"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/dbutil"
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+var _ SessionsStore = (*sessions)(nil)
+
+// Sessions is the default instance of the SessionsStore.
+var Sessions SessionsStore
+
+// SessionsStore is the persistent interface for sessions.
+type SessionsStore interface {
+	// Create creates a new session for the given user, returning the session
+	// and the plain token which should be sent to the client. The expired and
+	// deleted sessions of the user are purged.
+	Create(ctx context.Context, options CreateSessionOptions) (*Session, string, error)
+	// GetByToken retrieves an unexpired session by its plain token.
+	// It returns ErrSessionNotFound when the session does not exist or has expired.
+	GetByToken(ctx context.Context, token string) (*Session, error)
+	// DeleteByToken removes a session by its plain token.
+	DeleteByToken(ctx context.Context, token string) error
+	// DeleteByUserID removes all sessions of the given user.
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+// NewSessionsStore returns a SessionsStore instance with the given database connection.
+func NewSessionsStore(db *gorm.DB) SessionsStore {
+	return &sessions{db}
+}
+
+// Session is a server-side login session of a user.
+type Session struct {
+	dbutil.Model
+	// Token is the SHA-256 hash of the token held by the client.
+	Token     string `gorm:"uniqueIndex"`
+	UserID    uint   `gorm:"index"`
+	ExpiresAt time.Time
+}
+
+// hashSessionToken returns the hex-encoded SHA-256 hash of the given token,
+// so that a leaked sessions table can't be used to hijack sessions.
+func hashSessionToken(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
+
+type sessions struct {
+	*gorm.DB
+}
+
+type CreateSessionOptions struct {
+	UserID uint
+	MaxAge time.Duration
+}
+
+func (db *sessions) Create(ctx context.Context, options CreateSessionOptions) (*Session, string, error) {
+	// Purge on sign-in so that the table doesn't grow with the sessions that
+	// can never be used again.
+	if err := db.WithContext(ctx).Unscoped().
+		Where("user_id = ? AND (expires_at <= ? OR deleted_at IS NOT NULL)", options.UserID, dbutil.Now()).
+		Delete(&Session{}).Error; err != nil {
+		return nil, "", errors.Wrap(err, "purge sessions")
+	}
+
+	token := randstr.Hex(64)
+	session := &Session{
+		Token:     hashSessionToken(token),
+		UserID:    options.UserID,
+		ExpiresAt: dbutil.Now().Add(options.MaxAge),
+	}
+	if err := db.WithContext(ctx).Create(session).Error; err != nil {
+		return nil, "", errors.Wrap(err, "create session")
+	}
+	return session, token, nil
+}
+
+var ErrSessionNotFound = errs.NotFound("session_not_found", "Session does not exist")
+
+func (db *sessions) GetByToken(ctx context.Context, token string) (*Session, error) {
+	var session Session
+	if err := db.WithContext(ctx).
+		Where("token = ? AND expires_at > ?", hashSessionToken(token), dbutil.Now()).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, errors.Wrap(err, "get")
+	}
+	return &session, nil
+}
+
+func (db *sessions) DeleteByToken(ctx context.Context, token string) error {
+	return db.WithContext(ctx).Delete(&Session{}, "token = ?", hashSessionToken(token)).Error
+}
+
+func (db *sessions) DeleteByUserID(ctx context.Context, userID uint) error {
+	return db.WithContext(ctx).Delete(&Session{}, "user_id = ?", userID).Error
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// countSessions returns the number of the session rows of the user, including
+// the deleted ones.
+func countSessions(t *testing.T, db *gorm.DB, userID uint) int64 {
+	t.Helper()
+	var count int64
+	require.NoError(t, db.Unscoped().Model(&Session{}).Where("user_id = ?", userID).Count(&count).Error)
+	return count
+}
+
+func TestSessions_Create_Purge(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUsersStore(db)
+	sessions := NewSessionsStore(db)
+	ctx := context.Background()
+
+	alice, err := users.Create(ctx, CreateUserOptions{Email: "alice@example.com", Password: "correct horse", NickName: "alice"})
+	require.NoError(t, err)
+	bob, err := users.Create(ctx, CreateUserOptions{Email: "bob@example.com", Password: "correct horse", NickName: "bob"})
+	require.NoError(t, err)
+
+	_, expired, err := sessions.Create(ctx, CreateSessionOptions{UserID: alice.ID, MaxAge: -time.Hour})
+	require.NoError(t, err)
+	_, signedOut, err := sessions.Create(ctx, CreateSessionOptions{UserID: alice.ID, MaxAge: time.Hour})
+	require.NoError(t, err)
+	require.NoError(t, sessions.DeleteByToken(ctx, signedOut))
+	_, active, err := sessions.Create(ctx, CreateSessionOptions{UserID: alice.ID, MaxAge: time.Hour})
+	require.NoError(t, err)
+	_, _, err = sessions.Create(ctx, CreateSessionOptions{UserID: bob.ID, MaxAge: -time.Hour})
+	require.NoError(t, err)
+
+	_, err = sessions.GetByToken(ctx, expired)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+
+	// Signing in again purges the expired and the deleted sessions of the user,
+	// but not the active ones or the sessions of the other users.
+	_, _, err = sessions.Create(ctx, CreateSessionOptions{UserID: alice.ID, MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), countSessions(t, db, alice.ID))
+	assert.Equal(t, int64(1), countSessions(t, db, bob.ID))
+
+	_, err = sessions.GetByToken(ctx, active)
+	assert.NoError(t, err)
+}
+
+func TestUsers_Delete_Sessions(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUsersStore(db)
+	sessions := NewSessionsStore(db)
+	ctx := context.Background()
+
+	alice, err := users.Create(ctx, CreateUserOptions{Email: "alice@example.com", Password: "correct horse", NickName: "alice"})
+	require.NoError(t, err)
+	bob, err := users.Create(ctx, CreateUserOptions{Email: "bob@example.com", Password: "correct horse", NickName: "bob"})
+	require.NoError(t, err)
+
+	_, aliceToken, err := sessions.Create(ctx, CreateSessionOptions{UserID: alice.ID, MaxAge: time.Hour})
+	require.NoError(t, err)
+	_, bobToken, err := sessions.Create(ctx, CreateSessionOptions{UserID: bob.ID, MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, users.Delete(ctx, alice.ID))
+
+	_, err = sessions.GetByToken(ctx, aliceToken)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = sessions.GetByToken(ctx, bobToken)
+	assert.NoError(t, err)
+}
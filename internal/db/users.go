@@ -9,13 +9,18 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"fmt"
+	"net/netip"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/thanhpk/randstr"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/pbkdf2"
 	"gorm.io/gorm"
 
+	"github.com/wuhan005/go-template/internal/conf"
 	"github.com/wuhan005/go-template/internal/dbutil"
+	"github.com/wuhan005/go-template/internal/errs"
+	"github.com/wuhan005/go-template/internal/password"
 )
 
 var _ UsersStore = (*users)(nil)
@@ -26,19 +31,30 @@ var Users UsersStore
 // UsersStore is the persistent interface for users.
 type UsersStore interface {
 	// Authenticate checks the user's email and password, returning the user if valid.
-	// If the credentials are invalid, it returns ErrBadCredentials.
-	Authenticate(ctx context.Context, email, password string) (*User, error)
+	// If the credentials are invalid, it returns ErrBadCredentials. It returns
+	// ErrLoginLocked if the account or the client IP is locked for too many
+	// failed attempts.
+	Authenticate(ctx context.Context, options AuthenticateOptions) (*User, error)
 	// Create creates a new user with the given options.
+	// It returns ErrEmailTaken if the email is already used by another user.
 	Create(ctx context.Context, options CreateUserOptions) (*User, error)
 	// List retrieves a list of users based on the provided options.
 	List(ctx context.Context, options ListUsersOptions) ([]*User, int64, error)
+	// ListByCursor retrieves a page of users sorted by creation time in
+	// descending order using keyset pagination, along with the cursors of the
+	// adjacent pages. It returns dbutil.ErrInvalidCursor if the cursor is invalid.
+	ListByCursor(ctx context.Context, options ListUsersByCursorOptions) ([]*User, int64, dbutil.Cursors, error)
 	// GetByID retrieves a user by their ID.
 	GetByID(ctx context.Context, id string) (*User, error)
 	// GetByUID retrieves a user by their UID.
 	GetByUID(ctx context.Context, uid string) (*User, error)
+	// GetByEmail retrieves a user by their email.
+	GetByEmail(ctx context.Context, email string) (*User, error)
 	// Update updates the user with the given ID using the provided options.
 	Update(ctx context.Context, id uint, options UpdateUserOptions) error
-	// Delete removes a user by its ID
+	// ChangePassword sets a new password for the user with the given ID.
+	ChangePassword(ctx context.Context, id uint, password string) error
+	// Delete removes a user by its ID, along with the sessions of the user.
 	Delete(ctx context.Context, id uint) error
 }
 
@@ -49,8 +65,11 @@ func NewUsersStore(db *gorm.DB) UsersStore {
 
 type User struct {
 	dbutil.Model
-	Email    string
+	Email string
+	// Password is the password hash in the PHC string format. Legacy hashes are
+	// hex-encoded PBKDF2-SHA256 keys derived with Salt.
 	Password string
+	// Salt is only used by legacy password hashes.
 	Salt     string
 	NickName string
 }
@@ -60,42 +79,162 @@ func (u *User) BeforeCreate(db *gorm.DB) error {
 		return errors.Wrap(err, "before create model")
 	}
 
-	u.Salt = randstr.String(10)
-	u.EncodePassword()
+	if err := u.SetPassword(u.Password); err != nil {
+		return errors.Wrap(err, "set password")
+	}
+	return nil
+}
+
+// SetPassword hashes the given password with the configured algorithm and sets
+// it to the user.
+func (u *User) SetPassword(plain string) error {
+	hash, err := password.Hash(plain)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	u.Salt = ""
 	return nil
 }
 
-// EncodePassword hashes the user's password using PBKDF2 with SHA-256.
-func (u *User) EncodePassword() {
-	newPassword := pbkdf2.Key([]byte(u.Password), []byte(u.Salt), 10000, 50, sha256.New)
-	u.Password = fmt.Sprintf("%x", newPassword)
+// encodeLegacyPassword hashes the password using PBKDF2 with SHA-256, which is
+// kept to verify the passwords of users created before PHC hashes.
+func encodeLegacyPassword(plain, salt string) string {
+	return fmt.Sprintf("%x", pbkdf2.Key([]byte(plain), []byte(salt), 10000, 50, sha256.New))
 }
 
 // ValidatePassword checks if given password matches the one belongs to the user.
-func (u *User) ValidatePassword(password string) bool {
-	newUser := &User{Password: password, Salt: u.Salt}
-	newUser.EncodePassword()
-	return subtle.ConstantTimeCompare([]byte(u.Password), []byte(newUser.Password)) == 1
+// The returned needsRehash reports whether the stored hash is outdated and
+// should be replaced using SetPassword.
+func (u *User) ValidatePassword(plain string) (ok, needsRehash bool) {
+	if !password.IsPHC(u.Password) {
+		ok = subtle.ConstantTimeCompare([]byte(u.Password), []byte(encodeLegacyPassword(plain, u.Salt))) == 1
+		return ok, ok
+	}
+
+	ok, needsRehash, err := password.Verify(plain, u.Password)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", u.ID).Error("Failed to verify password")
+		return false, false
+	}
+	return ok, needsRehash
 }
 
 type users struct {
 	*gorm.DB
 }
 
-var ErrBadCredentials = errors.New("invalid email or password")
+var (
+	ErrBadCredentials = errs.Unauthorized("bad_credentials", "Invalid email or password")
+	ErrLoginLocked    = errs.RateLimited("login_locked", "Too many failed sign-in attempts, please try again later")
+)
+
+type AuthenticateOptions struct {
+	Email    string
+	Password string
+	// IP is the client IP, whose failed attempts are tracked if it is valid.
+	IP netip.Addr
+}
+
+// lockoutPolicy returns the configured lockout policy with the max attempts.
+func lockoutPolicy(maxAttempts int) LockoutPolicy {
+	return LockoutPolicy{
+		MaxAttempts: maxAttempts,
+		Duration:    conf.Lockout.Duration,
+		MaxDuration: conf.Lockout.MaxDuration,
+		Window:      conf.Lockout.Window,
+	}
+}
+
+func (db *users) Authenticate(ctx context.Context, options AuthenticateOptions) (*User, error) {
+	attempts := &loginAttempts{db.DB}
+	userPolicy, ipPolicy := lockoutPolicy(conf.Lockout.MaxAttempts), lockoutPolicy(conf.Lockout.IPMaxAttempts)
+
+	var ipKey string
+	if options.IP.IsValid() && ipPolicy.MaxAttempts > 0 {
+		ipKey = IPLoginKey(options.IP)
+		if err := checkLoginLocked(ctx, attempts, ipKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// The email is checked before the lookup, so that the emails of no account
+	// are locked the same way as the existing ones.
+	var emailKey string
+	if userPolicy.MaxAttempts > 0 {
+		emailKey = EmailLoginKey(options.Email)
+		if err := checkLoginLocked(ctx, attempts, emailKey); err != nil {
+			return nil, err
+		}
+	}
 
-func (db *users) Authenticate(ctx context.Context, email, password string) (*User, error) {
 	var user User
-	if err := db.WithContext(ctx).Model(&User{}).Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, ErrBadCredentials
+	if err := db.WithContext(ctx).Model(&User{}).Where("LOWER(email) = LOWER(?)", options.Email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Take as long as a wrong password to not leak whether the email exists.
+			password.VerifyDummy(options.Password)
+			recordLoginFailure(ctx, attempts, emailKey, userPolicy)
+			recordLoginFailure(ctx, attempts, ipKey, ipPolicy)
+			return nil, ErrBadCredentials
+		}
+		return nil, errors.Wrap(err, "get user")
 	}
 
-	if !user.ValidatePassword(password) {
+	ok, needsRehash := user.ValidatePassword(options.Password)
+	if !ok {
+		recordLoginFailure(ctx, attempts, emailKey, userPolicy)
+		recordLoginFailure(ctx, attempts, ipKey, ipPolicy)
 		return nil, ErrBadCredentials
 	}
+
+	// The failures of the IP are kept, otherwise an attacker could reset them
+	// by signing in to their own account.
+	if emailKey != "" {
+		if err := attempts.Reset(ctx, emailKey); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Warn("Failed to reset failed sign-in attempts")
+		}
+	}
+
+	// Upgrade the outdated hash in place while we have the plain password, the
+	// sign-in should not fail because of it though.
+	if needsRehash {
+		if err := db.ChangePassword(ctx, user.ID, options.Password); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("user_id", user.ID).Warn("Failed to rehash password")
+		}
+	}
 	return &user, nil
 }
 
+// checkLoginLocked returns ErrLoginLocked if the key is locked.
+func checkLoginLocked(ctx context.Context, attempts *loginAttempts, key string) error {
+	lockedUntil, err := attempts.LockedUntil(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, "get lockout")
+	}
+	if !lockedUntil.IsZero() {
+		return ErrLoginLocked
+	}
+	return nil
+}
+
+// recordLoginFailure records the failed attempt of the key if it is not empty.
+// The sign-in should not fail because of it, so the error is only logged.
+func recordLoginFailure(ctx context.Context, attempts *loginAttempts, key string, policy LockoutPolicy) {
+	if key == "" {
+		return
+	}
+	lockedUntil, err := attempts.RecordFailure(ctx, key, policy)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("key", key).Warn("Failed to record failed sign-in attempt")
+		return
+	}
+	if !lockedUntil.IsZero() {
+		logrus.WithContext(ctx).WithField("key", key).WithField("locked_until", lockedUntil).Warn("Locked for too many failed sign-in attempts")
+	}
+}
+
+var ErrEmailTaken = errs.Conflict("email_taken", "Email has already been taken")
+
 type CreateUserOptions struct {
 	Email    string
 	Password string
@@ -109,19 +248,59 @@ func (db *users) Create(ctx context.Context, options CreateUserOptions) (*User,
 		NickName: options.NickName,
 	}
 	if err := db.WithContext(ctx).Create(&newUser).Error; err != nil {
+		if dbutil.IsUniqueViolation(err, "users_email_unique") {
+			return nil, ErrEmailTaken
+		}
 		return nil, errors.Wrap(err, "create user")
 	}
 	return newUser, nil
 }
 
+// UserSortColumns is the whitelist of fields that users can be sorted by,
+// mapping to their column names.
+var UserSortColumns = map[string]string{
+	"id":        "id",
+	"email":     "email",
+	"nickName":  "nick_name",
+	"createdAt": "created_at",
+}
+
+// UsersFilter contains the conditions to filter users, zero values are ignored.
+type UsersFilter struct {
+	// Email filters the users with the email, case-insensitively.
+	Email string
+	// NickName filters the users whose nickname contains the given substring.
+	NickName string
+	// CreatedAfter and CreatedBefore filter the users by creation time.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+func (f UsersFilter) scope(query *gorm.DB) *gorm.DB {
+	if f.Email != "" {
+		query = query.Where("LOWER(email) = LOWER(?)", f.Email)
+	}
+	if f.NickName != "" {
+		query = query.Where("nick_name ILIKE ?", "%"+dbutil.EscapeLike(f.NickName)+"%")
+	}
+	if !f.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", f.CreatedAfter)
+	}
+	if !f.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", f.CreatedBefore)
+	}
+	return query
+}
+
 type ListUsersOptions struct {
 	dbutil.Pagination
+	UsersFilter
+	// Sort is the list of fields to sort by, defaults to ID in descending order.
+	Sort []dbutil.SortField
 }
 
 func (db *users) List(ctx context.Context, options ListUsersOptions) ([]*User, int64, error) {
-	query := db.WithContext(ctx).Model(&User{})
-
-	// TODO: where clause for filtering
+	query := db.WithContext(ctx).Model(&User{}).Scopes(options.UsersFilter.scope)
 
 	var count int64
 	if err := query.Count(&count).Error; err != nil {
@@ -130,14 +309,49 @@ func (db *users) List(ctx context.Context, options ListUsersOptions) ([]*User, i
 
 	limit, offset := options.LimitOffset()
 
+	// Always sort by ID at last to make the order stable.
+	orderBy := "id DESC"
+	if len(options.Sort) > 0 {
+		orderBy = dbutil.OrderBy(options.Sort) + ", " + orderBy
+	}
+
 	var users []*User
-	if err := query.Limit(limit).Offset(offset).Order("id DESC").Find(&users).Error; err != nil {
+	if err := query.Limit(limit).Offset(offset).Order(orderBy).Find(&users).Error; err != nil {
 		return nil, 0, errors.Wrap(err, "find")
 	}
 	return users, count, nil
 }
 
-var ErrUserNotFound = errors.New("user does not exist")
+type ListUsersByCursorOptions struct {
+	dbutil.KeysetPagination
+	UsersFilter
+}
+
+func (db *users) ListByCursor(ctx context.Context, options ListUsersByCursorOptions) ([]*User, int64, dbutil.Cursors, error) {
+	query := db.WithContext(ctx).Model(&User{}).Scopes(options.UsersFilter.scope)
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, dbutil.Cursors{}, errors.Wrap(err, "count")
+	}
+
+	keyset, err := options.KeysetPagination.Scope("created_at", true)
+	if err != nil {
+		return nil, 0, dbutil.Cursors{}, err
+	}
+
+	var users []*User
+	if err := query.Scopes(keyset).Find(&users).Error; err != nil {
+		return nil, 0, dbutil.Cursors{}, errors.Wrap(err, "find")
+	}
+
+	users, cursors := dbutil.KeysetResult(options.KeysetPagination, users, func(u *User) dbutil.Cursor {
+		return dbutil.Cursor{Key: u.CreatedAt.Format(time.RFC3339Nano), ID: u.ID}
+	})
+	return users, count, cursors, nil
+}
+
+var ErrUserNotFound = errs.NotFound("user_not_found", "User does not exist")
 
 func (db *users) getBy(ctx context.Context, where string, args ...interface{}) (*User, error) {
 	var user User
@@ -158,6 +372,10 @@ func (db *users) GetByUID(ctx context.Context, uid string) (*User, error) {
 	return db.getBy(ctx, "uid = ?", uid)
 }
 
+func (db *users) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return db.getBy(ctx, "LOWER(email) = LOWER(?)", email)
+}
+
 type UpdateUserOptions struct {
 	NickName string
 }
@@ -169,6 +387,28 @@ func (db *users) Update(ctx context.Context, id uint, options UpdateUserOptions)
 		}).Error
 }
 
+func (db *users) ChangePassword(ctx context.Context, id uint, password string) error {
+	var user User
+	if err := user.SetPassword(password); err != nil {
+		return errors.Wrap(err, "set password")
+	}
+
+	return db.WithContext(ctx).Model(&User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password": user.Password,
+			"salt":     user.Salt,
+		}).Error
+}
+
 func (db *users) Delete(ctx context.Context, id uint) error {
-	return db.WithContext(ctx).Delete(&User{}, "id = ?", id).Error
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&User{}, "id = ?", id).Error; err != nil {
+			return errors.Wrap(err, "delete user")
+		}
+		// The signed-in clients of the deleted user are signed out.
+		if err := NewSessionsStore(tx).DeleteByUserID(ctx, id); err != nil {
+			return errors.Wrap(err, "delete sessions")
+		}
+		return nil
+	})
 }
@@ -0,0 +1,38 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsers_Create_EmailTaken(t *testing.T) {
+	db := newTestDB(t)
+	users := NewUsersStore(db)
+	ctx := context.Background()
+
+	alice, err := users.Create(ctx, CreateUserOptions{Email: "A@example.com", Password: "correct horse", NickName: "alice"})
+	require.NoError(t, err)
+
+	// The emails are unique case-insensitively, which is enforced by the
+	// users_email_unique index on LOWER(email).
+	for _, email := range []string{"A@example.com", "a@example.com", "a@EXAMPLE.COM"} {
+		_, err := users.Create(ctx, CreateUserOptions{Email: email, Password: "correct horse", NickName: "bob"})
+		assert.ErrorIs(t, err, ErrEmailTaken, email)
+	}
+
+	got, err := users.GetByEmail(ctx, "a@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, got.ID)
+
+	// The email of a deleted user can be taken again.
+	require.NoError(t, users.Delete(ctx, alice.ID))
+	_, err = users.Create(ctx, CreateUserOptions{Email: "a@example.com", Password: "correct horse", NickName: "bob"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package dbtest creates the Postgres databases for the tests.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	// Register the "pgx" driver of database/sql.
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/thanhpk/randstr"
+)
+
+// NewDatabase creates an empty database for the test, which is dropped after
+// the test, and returns its DSN. The server is configured by the libpq
+// environment variables, e.g. PGHOST and PGUSER, and the test is skipped if
+// PGHOST is not set.
+func NewDatabase(t testing.TB) string {
+	t.Helper()
+	if os.Getenv("PGHOST") == "" {
+		t.Skip("PGHOST is not set, skipping the database test")
+	}
+
+	admin, err := sql.Open("pgx", "")
+	if err != nil {
+		t.Fatalf("open admin connection: %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	ctx := context.Background()
+	name := "go_template_test_" + strings.ToLower(randstr.String(12))
+	if _, err := admin.ExecContext(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("create database: %v", err)
+	}
+	t.Cleanup(func() {
+		// Terminate the connections left open by the test.
+		if _, err := admin.ExecContext(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)"); err != nil {
+			t.Errorf("drop database: %v", err)
+		}
+	})
+	return "dbname=" + name
+}
+
+// Open opens the database of the DSN with the "pgx" driver, which is closed
+// after the test.
+func Open(t testing.TB, dsn string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dbutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thanhpk/randstr"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+// CursorSecret is the key to sign the cursors, so that clients can't forge
+// cursors to probe arbitrary sort keys. It defaults to a random key, which
+// means cursors don't survive restarts and are not shared between instances.
+var CursorSecret = randstr.Bytes(32)
+
+// ErrInvalidCursor is returned when the cursor is malformed or the signature
+// doesn't match.
+var ErrInvalidCursor = errs.Invalid("invalid_cursor", "Invalid cursor")
+
+// Cursor is the position of a row in keyset pagination.
+type Cursor struct {
+	// Key is the value of the sort column of the row.
+	Key string `json:"k"`
+	// ID is the ID of the row, used to break ties of the sort key.
+	ID uint `json:"i"`
+}
+
+// Encode returns the opaque signed representation of the cursor.
+func (c Cursor) Encode() string {
+	payload, _ := json.Marshal(c)
+	mac := hmac.New(sha256.New, CursorSecret)
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// DecodeCursor parses and verifies the cursor encoded by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	payloadStr, signatureStr, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(signatureStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	mac := hmac.New(sha256.New, CursorSecret)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Cursors contains the cursors of the adjacent pages, which are empty if there
+// is no such page.
+type Cursors struct {
+	Next string
+	Prev string
+}
+
+// KeysetPagination represents keyset (cursor-based) pagination parameters.
+// At most one of After and Before should be set, and the first page is
+// returned if neither is set.
+type KeysetPagination struct {
+	// After is the cursor that the page starts after.
+	After string
+	// Before is the cursor that the page ends before.
+	Before string
+	// PageSize is the maximum number of rows of the page.
+	PageSize int
+}
+
+// Normalize ensures that the pagination parameters are valid. The page size
+// is clamped to MaxPageSize.
+func (p KeysetPagination) Normalize() KeysetPagination {
+	if p.PageSize <= 0 {
+		p.PageSize = DefaultPageSize
+	} else if p.PageSize > MaxPageSize {
+		p.PageSize = MaxPageSize
+	}
+	return p
+}
+
+// Scope returns a GORM scope that filters and sorts the query by the given
+// column and the ID. It fetches one more row than the page size, which is
+// used by KeysetResult to tell if there is another page.
+func (p KeysetPagination) Scope(column string, desc bool) (func(*gorm.DB) *gorm.DB, error) {
+	p = p.Normalize()
+
+	var cursor *Cursor
+	var err error
+	backward := p.Before != ""
+	if backward {
+		cursor, err = DecodeCursor(p.Before)
+	} else if p.After != "" {
+		cursor, err = DecodeCursor(p.After)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows before the cursor are queried in the reverse order, and then
+	// reversed back by KeysetResult.
+	comparator, order := ">", "ASC"
+	if desc != backward {
+		comparator, order = "<", "DESC"
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if cursor != nil {
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparator), cursor.Key, cursor.ID)
+		}
+		return db.Order(fmt.Sprintf("%s %s, id %s", column, order, order)).Limit(p.PageSize + 1)
+	}, nil
+}
+
+// KeysetResult trims the extra row fetched with the scope returned by
+// KeysetPagination.Scope, and returns the rows in order with the cursors of
+// the adjacent pages.
+func KeysetResult[T any](p KeysetPagination, rows []T, cursorOf func(T) Cursor) ([]T, Cursors) {
+	p = p.Normalize()
+
+	hasMore := len(rows) > p.PageSize
+	if hasMore {
+		rows = rows[:p.PageSize]
+	}
+
+	backward := p.Before != ""
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	var cursors Cursors
+	if len(rows) == 0 {
+		return rows, cursors
+	}
+	if backward || hasMore {
+		cursors.Next = cursorOf(rows[len(rows)-1]).Encode()
+	}
+	if (backward && hasMore) || (!backward && p.After != "") {
+		cursors.Prev = cursorOf(rows[0]).Encode()
+	}
+	return rows, cursors
+}
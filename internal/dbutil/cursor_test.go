@@ -0,0 +1,179 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dbutil
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Key: "2025-01-02T03:04:05Z", ID: 42}
+	encoded := cursor.Encode()
+
+	t.Run("round trip", func(t *testing.T) {
+		got, err := DecodeCursor(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, cursor, *got)
+	})
+
+	payload, signature, ok := strings.Cut(encoded, ".")
+	require.True(t, ok)
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"k":"2025-01-02T03:04:05Z","i":1}`))
+	notJSON := base64.RawURLEncoding.EncodeToString([]byte("not json"))
+
+	for name, s := range map[string]string{
+		"empty":             "",
+		"no signature":      payload,
+		"empty signature":   payload + ".",
+		"forged payload":    forged + "." + signature,
+		"tampered":          payload + "." + strings.ToUpper(signature),
+		"invalid payload":   "!!!." + signature,
+		"invalid signature": payload + ".!!!",
+		"not JSON":          notJSON + "." + signature,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeCursor(s)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+
+	t.Run("secret", func(t *testing.T) {
+		// The fallback key is random, rather than empty or fixed.
+		assert.Len(t, CursorSecret, 32)
+
+		saved := CursorSecret
+		t.Cleanup(func() { CursorSecret = saved })
+
+		CursorSecret = []byte("another secret")
+		_, err := DecodeCursor(encoded)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "cursors signed with another key should be rejected")
+
+		got, err := DecodeCursor(cursor.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, cursor, *got)
+	})
+}
+
+func TestKeysetPagination_Normalize(t *testing.T) {
+	for pageSize, want := range map[int]int{
+		-1:              DefaultPageSize,
+		0:               DefaultPageSize,
+		1:               1,
+		MaxPageSize:     MaxPageSize,
+		MaxPageSize + 1: MaxPageSize,
+		1000000:         MaxPageSize,
+	} {
+		got := KeysetPagination{PageSize: pageSize}.Normalize()
+		assert.Equal(t, want, got.PageSize, "page size %d", pageSize)
+	}
+}
+
+type row struct {
+	ID  uint
+	Key string
+}
+
+func cursorOf(r row) Cursor {
+	return Cursor{Key: r.Key, ID: r.ID}
+}
+
+// rows returns the rows of the IDs in order.
+func rows(ids ...uint) []row {
+	rows := make([]row, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, row{ID: id, Key: strconv.Itoa(int(id))})
+	}
+	return rows
+}
+
+func TestKeysetResult(t *testing.T) {
+	after := Cursor{Key: "3", ID: 3}.Encode()
+	before := Cursor{Key: "7", ID: 7}.Encode()
+
+	tests := []struct {
+		name       string
+		pagination KeysetPagination
+		rows       []row
+		wantRows   []row
+		wantNext   *row
+		wantPrev   *row
+	}{
+		{
+			name:       "first page",
+			pagination: KeysetPagination{PageSize: 2},
+			rows:       rows(1, 2, 3),
+			wantRows:   rows(1, 2),
+			wantNext:   &row{ID: 2, Key: "2"},
+		},
+		{
+			name:       "only page",
+			pagination: KeysetPagination{PageSize: 2},
+			rows:       rows(1, 2),
+			wantRows:   rows(1, 2),
+		},
+		{
+			name:       "middle page",
+			pagination: KeysetPagination{After: after, PageSize: 2},
+			rows:       rows(4, 5, 6),
+			wantRows:   rows(4, 5),
+			wantNext:   &row{ID: 5, Key: "5"},
+			wantPrev:   &row{ID: 4, Key: "4"},
+		},
+		{
+			name:       "last page",
+			pagination: KeysetPagination{After: after, PageSize: 2},
+			rows:       rows(4),
+			wantRows:   rows(4),
+			wantPrev:   &row{ID: 4, Key: "4"},
+		},
+		{
+			name:       "backward with more",
+			pagination: KeysetPagination{Before: before, PageSize: 2},
+			// The rows before the cursor are queried in the reverse order.
+			rows:     rows(6, 5, 4),
+			wantRows: rows(5, 6),
+			wantNext: &row{ID: 6, Key: "6"},
+			wantPrev: &row{ID: 5, Key: "5"},
+		},
+		{
+			name:       "backward to the first page",
+			pagination: KeysetPagination{Before: before, PageSize: 2},
+			rows:       rows(6, 5),
+			wantRows:   rows(5, 6),
+			wantNext:   &row{ID: 6, Key: "6"},
+		},
+		{
+			name:       "empty",
+			pagination: KeysetPagination{After: after, PageSize: 2},
+			rows:       nil,
+			wantRows:   nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotRows, cursors := KeysetResult(tc.pagination, tc.rows, cursorOf)
+			assert.Equal(t, tc.wantRows, gotRows)
+
+			assertCursor := func(want *row, got string) {
+				t.Helper()
+				if want == nil {
+					assert.Empty(t, got)
+					return
+				}
+				cursor, err := DecodeCursor(got)
+				require.NoError(t, err)
+				assert.Equal(t, cursorOf(*want), *cursor)
+			}
+			assertCursor(tc.wantNext, cursors.Next)
+			assertCursor(tc.wantPrev, cursors.Prev)
+		})
+	}
+}
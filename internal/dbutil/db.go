@@ -6,9 +6,10 @@ package dbutil
 
 import (
 	"database/sql"
-	"fmt"
 	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
@@ -17,10 +18,24 @@ type Transactor interface {
 	Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) (err error)
 }
 
-// IsUniqueViolation checks if the given error is a unique constraint violation.
+// uniqueViolationCode is the Postgres error code of "unique_violation".
+const uniqueViolationCode = "23505"
+
+// IsUniqueViolation checks if the given error is a unique constraint violation
+// of the given constraint or unique index, or of any one if the constraint is
+// empty.
 func IsUniqueViolation(err error, constraint string) bool {
-	if err != nil {
-		return strings.Contains(err.Error(), fmt.Sprintf("unique constraint %q", constraint))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == uniqueViolationCode && (constraint == "" || pgErr.ConstraintName == constraint)
 	}
 	return false
 }
+
+// EscapeLike escapes the wildcard characters of the given string so that it can
+// be used as a literal in a LIKE pattern.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -7,20 +7,20 @@ package dbutil
 // DefaultPageSize is the default number of items per page for pagination.
 var DefaultPageSize = 20
 
+// MaxPageSize is the maximum number of items per page, which bounds the rows
+// a single request can fetch.
+var MaxPageSize = 100
+
 // Pagination represents pagination parameters for database queries.
 type Pagination struct {
 	Page     int
 	PageSize int
 }
 
-// Normalize ensures that the pagination parameters are valid.
+// Normalize ensures that the pagination parameters are valid. The page size
+// is clamped to MaxPageSize.
 func (p Pagination) Normalize() Pagination {
-	if p.Page <= 0 {
-		p.Page = 1
-	}
-	if p.PageSize <= 0 {
-		p.PageSize = DefaultPageSize
-	}
+	p.Page, p.PageSize = normalizePage(p.Page, p.PageSize)
 	return p
 }
 
@@ -32,11 +32,20 @@ func (p Pagination) LimitOffset() (limit, offset int) {
 // LimitOffset returns LIMIT and OFFSET parameter for SQL.
 // The first page is page 0.
 func LimitOffset(page, pageSize int) (limit, offset int) {
+	page, pageSize = normalizePage(page, pageSize)
+	return pageSize, (page - 1) * pageSize
+}
+
+// normalizePage returns the page starting from 1, and the page size defaulting
+// to DefaultPageSize and clamped to MaxPageSize.
+func normalizePage(page, pageSize int) (int, int) {
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize < 1 {
+	if pageSize <= 0 {
 		pageSize = DefaultPageSize
+	} else if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
 	}
-	return pageSize, (page - 1) * pageSize
+	return page, pageSize
 }
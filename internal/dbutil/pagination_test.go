@@ -0,0 +1,37 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dbutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination Pagination
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "defaults", pagination: Pagination{}, wantLimit: DefaultPageSize, wantOffset: 0},
+		{name: "negative", pagination: Pagination{Page: -1, PageSize: -1}, wantLimit: DefaultPageSize, wantOffset: 0},
+		{name: "second page", pagination: Pagination{Page: 2, PageSize: 10}, wantLimit: 10, wantOffset: 10},
+		{name: "max page size", pagination: Pagination{Page: 1, PageSize: MaxPageSize}, wantLimit: MaxPageSize, wantOffset: 0},
+		{name: "clamped", pagination: Pagination{Page: 3, PageSize: 1000000}, wantLimit: MaxPageSize, wantOffset: 2 * MaxPageSize},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, offset := tc.pagination.LimitOffset()
+			assert.Equal(t, tc.wantLimit, limit)
+			assert.Equal(t, tc.wantOffset, offset)
+
+			normalized := tc.pagination.Normalize()
+			assert.Equal(t, tc.wantLimit, normalized.PageSize)
+			assert.Equal(t, tc.wantOffset/tc.wantLimit+1, normalized.Page)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dbutil
+
+import (
+	"strings"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+// SortField represents a column to sort the query results by.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort parses a comma-separated list of sort keys, e.g. "-createdAt,nickName",
+// where a leading "-" means descending order. Only the keys in the given
+// whitelist are accepted, and they are mapped to their column names. The
+// unsupported keys are rejected with the "invalid_sort" error.
+func ParseSort(s string, columns map[string]string) ([]SortField, error) {
+	var fields []SortField
+	for _, key := range strings.Split(s, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		column, ok := columns[key]
+		if !ok {
+			// The key is the argument of the localized message.
+			return nil, errs.Invalid("invalid_sort", "Unsupported sort field %q", key)
+		}
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+	return fields, nil
+}
+
+// OrderBy returns the ORDER BY clause for the given sort fields.
+func OrderBy(fields []SortField) string {
+	clauses := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			clauses = append(clauses, field.Column+" DESC")
+		} else {
+			clauses = append(clauses, field.Column+" ASC")
+		}
+	}
+	return strings.Join(clauses, ", ")
+}
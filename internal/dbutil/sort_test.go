@@ -0,0 +1,36 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package dbutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wuhan005/go-template/internal/errs"
+	"github.com/wuhan005/go-template/internal/i18n"
+)
+
+func TestParseSort(t *testing.T) {
+	columns := map[string]string{"createdAt": "created_at", "nickName": "nick_name"}
+
+	fields, err := ParseSort(" -createdAt, nickName,", columns)
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{{Column: "created_at", Desc: true}, {Column: "nick_name"}}, fields)
+	assert.Equal(t, "created_at DESC, nick_name ASC", OrderBy(fields))
+
+	fields, err = ParseSort("", columns)
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+
+	_, err = ParseSort("-password", columns)
+	var e *errs.Error
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, "invalid_sort", e.Code)
+	// The message is localized with the key only, without any English text.
+	assert.Equal(t, `不支持的排序字段 "password"`, i18n.Match("zh").Tr("error."+e.Code, e.Args...))
+	assert.Equal(t, `Unsupported sort field "password"`, i18n.Match("en").Tr("error."+e.Code, e.Args...))
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package errs provides the typed domain errors that are mapped to the HTTP
+// responses by the return handler.
+package errs
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is a domain error carrying the HTTP status and a machine-readable
+// code. The message is safe to be shown to clients, while the cause is only
+// used for logging.
+type Error struct {
+	// Status is the HTTP status code of the error.
+	Status int
+	// Code is the machine-readable code of the error, e.g. "user_not_found".
+	Code string
+	// Message is the human-readable message of the error in English.
+	Message string
+	// Args are the arguments to format the localized message of the code.
+	Args []interface{}
+	// Fields lists the invalid fields of the request, if any.
+	Fields []FieldError
+	// Cause is the underlying error, which is never exposed to clients.
+	Cause error
+}
+
+// FieldError describes an invalid field of the request.
+type FieldError struct {
+	// Pointer is the JSON pointer to the field, e.g. "/email".
+	Pointer string `json:"pointer"`
+	// Rule is the validation rule that the field fails, e.g. "required".
+	Rule string `json:"rule"`
+	// Message is the localized message of the error.
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the target is an *Error with the same status and code,
+// so that errors derived from a sentinel error still match it.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Status == e.Status && t.Code == e.Code
+}
+
+// WithFields returns a copy of the error with the given invalid fields.
+func (e *Error) WithFields(fields []FieldError) *Error {
+	newErr := *e
+	newErr.Fields = fields
+	return &newErr
+}
+
+// WithCause returns a copy of the error with the given cause.
+func (e *Error) WithCause(err error) *Error {
+	newErr := *e
+	newErr.Cause = err
+	return &newErr
+}
+
+// New returns an error with the given HTTP status, for the statuses without a
+// dedicated constructor.
+func New(status int, code, format string, args ...interface{}) *Error {
+	return newError(status, code, format, args...)
+}
+
+func newError(status int, code, format string, args ...interface{}) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Args:    args,
+	}
+}
+
+// NotFound returns an error that the requested resource does not exist.
+func NotFound(code, format string, args ...interface{}) *Error {
+	return newError(http.StatusNotFound, code, format, args...)
+}
+
+// Conflict returns an error that the request conflicts with the current state
+// of the resource.
+func Conflict(code, format string, args ...interface{}) *Error {
+	return newError(http.StatusConflict, code, format, args...)
+}
+
+// Invalid returns an error that the request is malformed or invalid.
+func Invalid(code, format string, args ...interface{}) *Error {
+	return newError(http.StatusBadRequest, code, format, args...)
+}
+
+// Unauthorized returns an error that the request is not authenticated.
+func Unauthorized(code, format string, args ...interface{}) *Error {
+	return newError(http.StatusUnauthorized, code, format, args...)
+}
+
+// Forbidden returns an error that the request is not allowed.
+func Forbidden(code, format string, args ...interface{}) *Error {
+	return newError(http.StatusForbidden, code, format, args...)
+}
+
+// RateLimited returns an error that the client has sent too many requests.
+func RateLimited(code, format string, args ...interface{}) *Error {
+	return newError(http.StatusTooManyRequests, code, format, args...)
+}
+
+// Internal is the error that hides the details of unexpected errors.
+var Internal = newError(http.StatusInternalServerError, "internal", "Internal server error")
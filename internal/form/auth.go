@@ -0,0 +1,13 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package form
+
+// Login is used for signing in a user.
+type Login struct {
+	// Email is the user's email address.
+	Email string `json:"email" valid:"required;email" label:"电子邮箱" label-en:"Email"`
+	// Password is the user's password.
+	Password string `json:"password" valid:"required" label:"密码" label-en:"Password"`
+}
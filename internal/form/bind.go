@@ -0,0 +1,357 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package form
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/wuhan005/go-template/internal/errs"
+	"github.com/wuhan005/go-template/internal/i18n"
+)
+
+// maxMemory is the maximum bytes of a multipart form to be stored in memory,
+// the rest of the files are stored on disk.
+const maxMemory = 32 << 20
+
+var (
+	// ErrBodyTooLarge is returned when the request body exceeds the limit.
+	ErrBodyTooLarge = errs.New(http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large")
+	// ErrUnsupportedMediaType is returned when the request body is not in a supported format.
+	ErrUnsupportedMediaType = errs.New(http.StatusUnsupportedMediaType, "unsupported_media_type", "Unsupported media type")
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// bindSource is a source of the values tagged on the form fields.
+type bindSource struct {
+	tag    string
+	values func(name string) ([]string, bool)
+}
+
+// bindBody decodes the request body into obj according to its content type.
+// JSON is assumed if the content type is absent.
+func bindBody(r *http.Request, obj interface{}, strict bool, locale *i18n.Locale) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	mediaType := "application/json"
+	if contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return ErrUnsupportedMediaType.WithCause(err)
+		}
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(r.Body)
+		if strict {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			// The decoder doesn't have a typed error for unknown fields.
+			if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+				name, _ = strconv.Unquote(name)
+				return unknownFieldsError([]string{name}, locale)
+			}
+			return bodyError(err, errs.Invalid("invalid_body", "Failed to parse form data"))
+		}
+		// Reject the trailing data after the JSON value, e.g. a second object.
+		if strict {
+			if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+				return bodyError(err, errs.Invalid("invalid_body", "Failed to parse form data"))
+			}
+		}
+		return nil
+
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return bodyError(err, errs.Invalid("invalid_body", "Failed to parse form data"))
+		}
+		return bindForm(obj, r.PostForm, nil, strict, locale)
+
+	case mediaType == "multipart/form-data":
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return bodyError(err, errs.Invalid("invalid_body", "Failed to parse form data"))
+		}
+		return bindForm(obj, r.MultipartForm.Value, r.MultipartForm.File, strict, locale)
+	}
+	return ErrUnsupportedMediaType
+}
+
+// bodyError returns ErrBodyTooLarge if the body exceeds the limit, otherwise
+// the given error with the cause.
+func bodyError(err error, invalid *errs.Error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrBodyTooLarge
+	}
+	return invalid.WithCause(err)
+}
+
+// bindForm binds the form values and files to the fields tagged with `form`
+// and `file`. Unknown keys are rejected in strict mode.
+func bindForm(obj interface{}, values map[string][]string, files map[string][]*multipart.FileHeader, strict bool, locale *i18n.Locale) error {
+	v := reflect.ValueOf(obj).Elem()
+	known := make(map[string]bool)
+
+	err := bindValues(v, locale, bindSource{
+		tag: "form",
+		values: func(name string) ([]string, bool) {
+			known[name] = true
+			vals, ok := values[name]
+			return vals, ok
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := bindFiles(v, files, known); err != nil {
+		return err
+	}
+
+	if strict {
+		var unknown []string
+		for name := range values {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		for name := range files {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return unknownFieldsError(unknown, locale)
+		}
+	}
+	return nil
+}
+
+// unknownFieldsError returns the error that the fields are unknown.
+func unknownFieldsError(names []string, locale *i18n.Locale) error {
+	fields := make([]errs.FieldError, 0, len(names))
+	for _, name := range names {
+		fields = append(fields, errs.FieldError{
+			Pointer: "/" + name,
+			Rule:    "unknown",
+			Message: locale.Tr("error.unknown_field", name),
+		})
+	}
+	return errs.Invalid("unknown_field", "Unknown field %q", names[0]).WithFields(fields)
+}
+
+// bindFiles sets the uploaded files to the fields tagged with `file`, which
+// must be of the type *multipart.FileHeader or []*multipart.FileHeader.
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader, known map[string]bool) error {
+	return walkFields(v, "file", func(field reflect.Value, name string) error {
+		known[name] = true
+		headers := files[name]
+		if len(headers) == 0 {
+			return nil
+		}
+
+		switch field.Type() {
+		case fileHeaderType:
+			field.Set(reflect.ValueOf(headers[0]))
+		case fileHeadersType:
+			field.Set(reflect.ValueOf(headers))
+		default:
+			return errors.Errorf("unsupported type of the file field %q: %s", name, field.Type())
+		}
+		return nil
+	})
+}
+
+// bindValues sets the values of the source to the fields tagged with the tag
+// of the source. Fields without values are left untouched.
+func bindValues(v reflect.Value, locale *i18n.Locale, source bindSource) error {
+	return walkFields(v, source.tag, func(field reflect.Value, name string) error {
+		vals, ok := source.values(name)
+		if !ok || len(vals) == 0 {
+			return nil
+		}
+		if err := setValue(field, vals); err != nil {
+			return errs.Invalid("invalid_field", "Invalid value of %q", name).
+				WithFields([]errs.FieldError{{Pointer: "/" + name, Rule: "type", Message: locale.Tr("error.invalid_field", name)}}).
+				WithCause(err)
+		}
+		return nil
+	})
+}
+
+// walkFields calls fn with the fields having the tag, including the fields of
+// the embedded structs.
+func walkFields(v reflect.Value, tag string, fn func(field reflect.Value, name string) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			if err := walkFields(v.Field(i), tag, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(structField.Tag.Get(tag), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if err := fn(v.Field(i), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkModel checks that the fields bound from the sources are of the
+// supported types, including the fields of the embedded structs.
+func checkModel(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return errors.Errorf("binding model must be a struct, got %s", t)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct {
+			if err := checkModel(structField.Type); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, tag := range []string{"form", "query", "path"} {
+			if name, _, _ := strings.Cut(structField.Tag.Get(tag), ","); name != "" && name != "-" && !supportedType(structField.Type) {
+				return errors.Errorf("unsupported type of the %s field %q: %s", tag, name, structField.Type)
+			}
+		}
+		if name, _, _ := strings.Cut(structField.Tag.Get("file"), ","); name != "" && name != "-" &&
+			structField.Type != fileHeaderType && structField.Type != fileHeadersType {
+			return errors.Errorf("unsupported type of the file field %q: %s", name, structField.Type)
+		}
+	}
+	return nil
+}
+
+// supportedType reports whether the values can be parsed into the type by
+// setValue.
+func supportedType(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setValue parses the string values into the field. Slices take all the
+// values, others take the first one.
+func setValue(field reflect.Value, vals []string) error {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setScalar(slice.Index(i), val); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setScalar(field, vals[0])
+}
+
+func setScalar(field reflect.Value, val string) error {
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setScalar(ptr.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return errors.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -5,51 +5,124 @@
 package form
 
 import (
-	"encoding/json"
 	"net/http"
 	"reflect"
 
 	"github.com/flamego/flamego"
-	"github.com/wuhan005/govalid"
-	"golang.org/x/text/language"
 
+	"github.com/wuhan005/go-template/internal/conf"
 	"github.com/wuhan005/go-template/internal/context"
+	"github.com/wuhan005/go-template/internal/errs"
+	"github.com/wuhan005/go-template/internal/i18n"
 )
 
-func Bind(model interface{}) flamego.Handler {
+// bindOptions contains the options of Bind.
+type bindOptions struct {
+	strict bool
+}
+
+// Option applies an option value for Bind.
+type Option interface {
+	apply(*bindOptions)
+}
+
+type strictOption struct{}
+
+func (strictOption) apply(o *bindOptions) {
+	o.strict = true
+}
+
+// Strict returns an Option to reject the request body with unknown fields.
+func Strict() Option {
+	return strictOption{}
+}
+
+// Bind returns a handler that binds the request to the model and validates
+// it. The fields are bound from the sources by their struct tags:
+//
+//   - `json`: the JSON body, which is assumed if the Content-Type is absent.
+//   - `form`: the application/x-www-form-urlencoded or multipart/form-data body.
+//   - `file`: the files of the multipart/form-data body.
+//   - `query`: the query string.
+//   - `path`: the path parameters of the route.
+//
+// The later sources take precedence. The request body is limited to
+// conf.App.MaxBodySize, and 413 is returned if it is exceeded.
+func Bind(model interface{}, opts ...Option) flamego.Handler {
 	// Ensure not pointer.
 	if reflect.TypeOf(model).Kind() == reflect.Ptr {
 		panic("form: pointer can not be accepted as binding model")
 	}
+	// Reject the unsupported field types on registration instead of on requests.
+	if err := checkModel(reflect.TypeOf(model)); err != nil {
+		panic("form: " + err.Error())
+	}
+
+	var options bindOptions
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
 
 	return func(ctx context.Context) error {
 		obj := reflect.New(reflect.TypeOf(model))
 		r := ctx.Request().Request
+
+		if maxBodySize := conf.App.MaxBodySize; maxBodySize > 0 && r.Body != nil {
+			if r.ContentLength > maxBodySize {
+				return ErrBodyTooLarge
+			}
+			r.Body = http.MaxBytesReader(ctx.ResponseWriter(), r.Body, maxBodySize)
+		}
 		if r.Body != nil {
 			defer func() { _ = r.Body.Close() }()
-			if err := json.NewDecoder(r.Body).Decode(obj.Interface()); err != nil {
-				return ctx.Error(http.StatusBadRequest, "Failed to parse form data")
-			}
 		}
 
-		acceptLanguage := r.Header.Get("Accept-Language")
-		languageTags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
-		languageTag := language.Chinese
-		if len(languageTags) > 0 {
-			languageTag = languageTags[0]
+		locale := ctx.Locale
+		if locale == nil {
+			locale = i18n.Match(r.Header.Get("Accept-Language"))
 		}
 
-		errors, ok := govalid.Check(obj.Interface(), languageTag)
-		if !ok {
-			var msg string
-			if len(errors) > 0 {
-				msg = errors[0].Error()
-			}
-			return ctx.Error(http.StatusBadRequest, "%s", msg)
+		err := bindBody(r, obj.Interface(), options.strict, locale)
+		// net/http only removes the temporary files of the multipart form parsed
+		// on the original request, not on the copies made by the middleware.
+		if r.MultipartForm != nil {
+			defer func() { _ = r.MultipartForm.RemoveAll() }()
+		}
+		if err != nil {
+			return err
+		}
+
+		query := r.URL.Query()
+		if err := bindValues(obj.Elem(), locale, bindSource{
+			tag: "query",
+			values: func(name string) ([]string, bool) {
+				vals, ok := query[name]
+				return vals, ok
+			},
+		}); err != nil {
+			return err
+		}
+
+		if err := bindValues(obj.Elem(), locale, bindSource{
+			tag: "path",
+			values: func(name string) ([]string, bool) {
+				val := ctx.Param(name)
+				return []string{val}, val != ""
+			},
+		}); err != nil {
+			return err
+		}
+
+		if fields := validate(obj.Interface(), locale.Tag); len(fields) > 0 {
+			return errs.Invalid("invalid_form", "%s", fields[0].Message).WithFields(fields)
 		}
 
 		// Validation passed.
 		ctx.Map(obj.Elem().Interface())
+		if r.MultipartForm != nil {
+			// Keep the uploaded files until the following handlers are done.
+			ctx.Next()
+		}
 		return nil
 	}
 }
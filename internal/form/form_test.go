@@ -0,0 +1,244 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package form
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/context"
+)
+
+// serve serves the request by the handlers of the route, which is mounted on
+// both "/" and "/{name}", and returns the response.
+func serve(t *testing.T, req *http.Request, handlers ...flamego.Handler) *httptest.ResponseRecorder {
+	t.Helper()
+	f := flamego.New()
+	f.Map(context.ReturnHandler())
+	f.Use(context.Contexter(&gorm.DB{}, nil))
+	f.Post("/", handlers...)
+	f.Post("/{name}", handlers...)
+
+	req.Header.Set("Accept-Language", "en")
+	resp := httptest.NewRecorder()
+	f.ServeHTTP(resp, req)
+	return resp
+}
+
+// errorCode returns the code of the error response.
+func errorCode(t *testing.T, resp *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body), resp.Body.String())
+	return body.Code
+}
+
+func TestBind_Multipart(t *testing.T) {
+	type upload struct {
+		Name string                `form:"name"`
+		File *multipart.FileHeader `file:"file"`
+	}
+
+	f := flamego.New()
+	f.Map(context.ReturnHandler())
+	f.Use(context.Contexter(&gorm.DB{}, nil))
+	f.Post("/", Bind(upload{}), func(form upload) string {
+		file, err := form.File.Open()
+		if err != nil {
+			return err.Error()
+		}
+		defer func() { _ = file.Close() }()
+		content, _ := io.ReadAll(file)
+		return form.Name + ":" + string(content)
+	})
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("name", "hello"))
+	part, err := writer.CreateFormFile("file", "hello.txt")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("world"))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp := httptest.NewRecorder()
+	f.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "hello:world", resp.Body.String())
+}
+
+func TestBind_UnsupportedType(t *testing.T) {
+	assert.PanicsWithValue(t, `form: unsupported type of the query field "filter": map[string]string`, func() {
+		Bind(struct {
+			Filter map[string]string `query:"filter"`
+		}{})
+	})
+	assert.PanicsWithValue(t, `form: unsupported type of the file field "avatar": string`, func() {
+		Bind(struct {
+			Avatar string `file:"avatar"`
+		}{})
+	})
+	assert.NotPanics(t, func() {
+		Bind(struct {
+			IDs   []uint  `query:"ids"`
+			Limit *int    `query:"limit"`
+			Name  string  `json:"name"`
+			Score float64 `form:"score"`
+		}{})
+	})
+}
+
+func TestBind_Strict(t *testing.T) {
+	type signUp struct {
+		Name string `json:"name" form:"name"`
+	}
+	echo := func(form signUp) string { return form.Name }
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "JSON", contentType: "application/json", body: `{"name":"alice","admin":true}`},
+		{name: "urlencoded", contentType: "application/x-www-form-urlencoded", body: "name=alice&admin=true"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			newRequest := func() *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", tc.contentType)
+				return req
+			}
+
+			resp := serve(t, newRequest(), Bind(signUp{}, Strict()), echo)
+			require.Equal(t, http.StatusBadRequest, resp.Code)
+			assert.Equal(t, "unknown_field", errorCode(t, resp))
+			assert.Contains(t, resp.Body.String(), `Unknown field \"admin\"`)
+
+			// The unknown fields are ignored unless in strict mode.
+			resp = serve(t, newRequest(), Bind(signUp{}), echo)
+			require.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, "alice", resp.Body.String())
+		})
+	}
+
+	t.Run("trailing data", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"alice"}{"name":"bob"}`))
+		resp := serve(t, req, Bind(signUp{}, Strict()), echo)
+		require.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "invalid_body", errorCode(t, resp))
+	})
+}
+
+func TestBind_BodyTooLarge(t *testing.T) {
+	appConf := conf.App
+	t.Cleanup(func() { conf.App = appConf })
+	conf.App.MaxBodySize = 16
+
+	type signUp struct {
+		Name string `json:"name" form:"name"`
+	}
+	echo := func(form signUp) string { return form.Name }
+	large := `{"name":"` + strings.Repeat("a", 32) + `"}`
+
+	t.Run("content length", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(large))
+		resp := serve(t, req, Bind(signUp{}), echo)
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+		assert.Equal(t, "body_too_large", errorCode(t, resp))
+	})
+
+	// The body of an unknown length is limited while it is read.
+	for name, contentType := range map[string]string{
+		"chunked JSON":       "application/json",
+		"chunked urlencoded": "application/x-www-form-urlencoded",
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := large
+			if contentType != "application/json" {
+				body = "name=" + strings.Repeat("a", 32)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(body)))
+			req.ContentLength = -1
+			req.Header.Set("Content-Type", contentType)
+			resp := serve(t, req, Bind(signUp{}), echo)
+			require.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+			assert.Equal(t, "body_too_large", errorCode(t, resp))
+		})
+	}
+
+	t.Run("within the limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"alice"}`))
+		resp := serve(t, req, Bind(signUp{}), echo)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "alice", resp.Body.String())
+	})
+}
+
+func TestBind_URLEncoded(t *testing.T) {
+	type search struct {
+		Name   string   `form:"name" valid:"required"`
+		Age    int      `form:"age"`
+		Tags   []string `form:"tag"`
+		Active *bool    `form:"active"`
+	}
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		return req
+	}
+
+	resp := serve(t, newRequest("name=alice&age=18&tag=a&tag=b&active=true"), Bind(search{}), func(form search) string {
+		b, _ := json.Marshal(form)
+		return string(b)
+	})
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.JSONEq(t, `{"Name":"alice","Age":18,"Tags":["a","b"],"Active":true}`, resp.Body.String())
+
+	resp = serve(t, newRequest("name=alice&age=eighteen"), Bind(search{}), func() string { return "ok" })
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestBind_Precedence(t *testing.T) {
+	type lookup struct {
+		Name string `json:"name" query:"name" path:"name"`
+	}
+	echo := func(form lookup) string { return form.Name }
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "body", target: "/", want: "body"},
+		{name: "query over body", target: "/?name=query", want: "query"},
+		{name: "path over query", target: "/path?name=query", want: "path"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(`{"name":"body"}`))
+			resp := serve(t, req, Bind(lookup{}), echo)
+			require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+			assert.Equal(t, tc.want, resp.Body.String())
+		})
+	}
+}
@@ -7,15 +7,15 @@ package form
 // CreateUser is used for creating a new user.
 type CreateUser struct {
 	// Email is the user's email address.
-	Email string `json:"email" valid:"required;email" label:"电子邮箱"`
+	Email string `json:"email" valid:"required;email" label:"电子邮箱" label-en:"Email"`
 	// Password is the user's password.
-	Password string `json:"password" valid:"required" label:"密码"`
+	Password string `json:"password" valid:"required" label:"密码" label-en:"Password"`
 	// NickName is the user's nickname.
-	NickName string `json:"nickName" valid:"required" label:"昵称"`
+	NickName string `json:"nickName" valid:"required" label:"昵称" label-en:"Nickname"`
 }
 
 // UpdateUser is used for updating user information.
 type UpdateUser struct {
 	// NickName is the user's nickname.
-	NickName string `json:"nickName" valid:"required" label:"昵称"`
+	NickName string `json:"nickName" valid:"required" label:"昵称" label-en:"Nickname"`
 }
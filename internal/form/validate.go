@@ -0,0 +1,138 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package form
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wuhan005/govalid"
+	"golang.org/x/text/language"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+// validate checks the form, and returns the invalid fields with the messages
+// localized to the given language.
+func validate(obj interface{}, languageTag language.Tag) []errs.FieldError {
+	errCtxs, ok := govalid.Check(obj, languageTag)
+	if ok {
+		return nil
+	}
+
+	typ := reflect.TypeOf(obj)
+	value := reflect.ValueOf(obj)
+	if typ.Kind() == reflect.Ptr {
+		typ, value = typ.Elem(), value.Elem()
+	}
+
+	// govalid doesn't tell which field and rule an error is of if the field has
+	// a custom message, so the failures are matched to the errors by order.
+	failures := fieldFailures(typ, value, languageTag)
+	fields := make([]errs.FieldError, 0, len(errCtxs))
+	for i, errCtx := range errCtxs {
+		fieldName, rule := errCtx.FieldName, ""
+		if i < len(failures) && (fieldName == "" || fieldName == failures[i].field) {
+			fieldName, rule = failures[i].field, failures[i].rule
+		}
+		fields = append(fields, errs.FieldError{
+			Pointer: jsonPointer(typ, fieldName),
+			Rule:    rule,
+			Message: errCtx.Error(),
+		})
+	}
+	return fields
+}
+
+// failure is a failed rule of a field.
+type failure struct {
+	field string
+	rule  string
+}
+
+// fieldFailures returns the failed rules of the fields in the order govalid
+// reports them, i.e. one per failed rule of a field in the order of the rules,
+// or only the first one if the field has a custom message.
+func fieldFailures(typ reflect.Type, value reflect.Value, languageTag language.Tag) []failure {
+	var failures []failure
+	if typ.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			failures = append(failures, fieldFailures(typ.Elem(), value.Index(i), languageTag)...)
+		}
+		return failures
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct || field.Type.Kind() == reflect.Struct {
+			failures = append(failures, fieldFailures(field.Type, value.Field(i), languageTag)...)
+		}
+
+		rawRules, ok := field.Tag.Lookup(govalid.RulesField)
+		if !ok {
+			continue
+		}
+		_, hasMessage := field.Tag.Lookup(govalid.MessageField)
+		for _, rule := range strings.Split(rawRules, ";") {
+			name, _, _ := strings.Cut(rule, ":")
+			if name == "" || ruleOK(typ, value, field, rule) {
+				continue
+			}
+			failures = append(failures, failure{field: field.Name, rule: name})
+			if hasMessage {
+				break
+			}
+		}
+	}
+	return failures
+}
+
+// ruleOK reports whether the field passes the rule, which is checked on its
+// own against a struct of only the field and the field it refers to.
+func ruleOK(typ reflect.Type, value reflect.Value, field reflect.StructField, rule string) bool {
+	if !field.IsExported() {
+		return true
+	}
+
+	fields := []reflect.StructField{{
+		Name: field.Name,
+		Type: field.Type,
+		Tag:  reflect.StructTag(fmt.Sprintf("%s:%q", govalid.RulesField, rule)),
+	}}
+	values := []reflect.Value{value.FieldByIndex(field.Index)}
+	if name, params, _ := strings.Cut(rule, ":"); name == "equal" {
+		if other, ok := typ.FieldByName(params); ok && other.IsExported() && other.Name != field.Name {
+			fields = append(fields, reflect.StructField{Name: other.Name, Type: other.Type})
+			values = append(values, value.FieldByIndex(other.Index))
+		}
+	}
+
+	probe := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, v := range values {
+		probe.Field(i).Set(v)
+	}
+	_, ok := govalid.Check(probe.Interface())
+	return ok
+}
+
+// jsonPointer returns the JSON pointer to the struct field by its JSON name.
+// It points to the whole document if the field is unknown, e.g. the errors
+// returned by the Validate method of the form.
+func jsonPointer(typ reflect.Type, fieldName string) string {
+	if typ.Kind() != reflect.Struct || fieldName == "" {
+		return ""
+	}
+	field, ok := typ.FieldByName(fieldName)
+	if !ok {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		name = field.Name
+	}
+	return "/" + name
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package form
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+
+	"github.com/wuhan005/go-template/internal/errs"
+)
+
+func TestValidate(t *testing.T) {
+	type form struct {
+		Email    string `json:"email" valid:"required;email" label-en:"Email"`
+		Name     string `json:"name" valid:"minlen:3;alpha" label-en:"Name"`
+		Password string `json:"password" valid:"required"`
+		Confirm  string `json:"confirm" valid:"equal:Password" msg:"Passwords do not match"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		got := validate(&form{Email: "a@example.com", Name: "abc", Password: "p", Confirm: "p"}, language.English)
+		assert.Empty(t, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		got := validate(&form{Email: "nope", Name: "1", Password: "p", Confirm: "q"}, language.English)
+		rules := make([]string, 0, len(got))
+		pointers := make([]string, 0, len(got))
+		for _, field := range got {
+			rules = append(rules, field.Rule)
+			pointers = append(pointers, field.Pointer)
+		}
+		assert.Equal(t, []string{"email", "minlen", "alpha", "equal"}, rules)
+		assert.Equal(t, []string{"/email", "/name", "/name", "/confirm"}, pointers)
+		assert.Equal(t, errs.FieldError{Pointer: "/confirm", Rule: "equal", Message: "Passwords do not match"}, got[3])
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package health serves the liveness, readiness and startup probes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Probe is the kind of health probe, following the Kubernetes probes.
+type Probe string
+
+const (
+	// Liveness reports whether the process should be restarted, which should
+	// not depend on the external dependencies.
+	Liveness Probe = "livez"
+	// Readiness reports whether the server is able to serve requests.
+	Readiness Probe = "readyz"
+	// Startup reports whether the server has finished starting up.
+	Startup Probe = "startupz"
+)
+
+// DefaultTimeout is the timeout of a check if it is registered without one.
+const DefaultTimeout = 3 * time.Second
+
+// CheckFunc checks a dependency, returning an error if it is unhealthy.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+	probes  []Probe
+}
+
+var (
+	mu      sync.RWMutex
+	checks  = make(map[string]check)
+	version string
+)
+
+// Register registers a check to the given probes, replacing the check of the
+// same name. The context passed to the check is canceled after the timeout.
+func Register(name string, timeout time.Duration, fn CheckFunc, probes ...Probe) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check{name: name, timeout: timeout, fn: fn, probes: probes}
+}
+
+// SetVersion sets the version reported in the verbose output.
+func SetVersion(v string) {
+	mu.Lock()
+	defer mu.Unlock()
+	version = v
+}
+
+// Result is the result of a check.
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the result of a probe.
+type Report struct {
+	Status  string            `json:"status"`
+	Version string            `json:"version,omitempty"`
+	Checks  map[string]Result `json:"checks,omitempty"`
+}
+
+// Healthy reports whether all the checks have passed.
+func (r *Report) Healthy() bool {
+	return r.Status == statusOK
+}
+
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+// Run runs the checks of the probe concurrently, skipping the excluded ones.
+func Run(ctx context.Context, probe Probe, exclude ...string) *Report {
+	mu.RLock()
+	var selected []check
+	for _, c := range checks {
+		if c.has(probe) && !contains(exclude, c.name) {
+			selected = append(selected, c)
+		}
+	}
+	report := &Report{
+		Status:  statusOK,
+		Version: version,
+		Checks:  make(map[string]Result, len(selected)),
+	}
+	mu.RUnlock()
+	sort.Slice(selected, func(i, j int) bool { return selected[i].name < selected[j].name })
+
+	results := make([]Result, len(selected))
+	var wg sync.WaitGroup
+	for i, c := range selected {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range selected {
+		if results[i].Status != statusOK {
+			report.Status = statusFail
+		}
+		report.Checks[c.name] = results[i]
+	}
+	return report
+}
+
+func (c check) has(probe Probe) bool {
+	for _, p := range c.probes {
+		if p == probe {
+			return true
+		}
+	}
+	return false
+}
+
+func (c check) run(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	result := Result{
+		Status:     statusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = errors.Errorf("timed out after %s", c.timeout)
+		}
+		result.Status = statusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns the handler of the probe for the public listener, which
+// responds 200 if all the checks pass and 503 otherwise. Only the overall
+// status is reported, so that the errors of the checks and the version are
+// not exposed to the clients.
+func Handler(probe Probe) http.Handler {
+	return handler(probe, false)
+}
+
+// AdminHandler returns the handler of the probe for the admin listener. Unlike
+// Handler, the result of each check is included with the `verbose` query
+// parameter, and checks can be skipped with the repeated `exclude` query
+// parameter, e.g. "/readyz?verbose&exclude=redis".
+func AdminHandler(probe Probe) http.Handler {
+	return handler(probe, true)
+}
+
+func handler(probe Probe, admin bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var exclude []string
+		if admin {
+			exclude = query["exclude"]
+		}
+		report := Run(r.Context(), probe, exclude...)
+
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+		if !admin || !verbose(query) {
+			report = &Report{Status: report.Status}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// verbose reports whether the verbose output is requested, i.e. the `verbose`
+// query parameter is present and not "false" or "0".
+func verbose(query url.Values) bool {
+	v := query.Get("verbose")
+	return query.Has("verbose") && v != "false" && v != "0"
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	Register("ok", 0, func(context.Context) error { return nil }, Readiness)
+	Register("broken", 0, func(context.Context) error { return errors.New("dial tcp 10.0.0.1:5432: refused") }, Readiness)
+	SetVersion("abc123")
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(checks, "ok")
+		delete(checks, "broken")
+		version = ""
+	})
+
+	serve := func(h http.Handler, target string) (int, Report) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var report Report
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return w.Code, report
+	}
+
+	t.Run("public ignores verbose and exclude", func(t *testing.T) {
+		code, report := serve(Handler(Readiness), "/readyz?verbose&exclude=broken")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, Report{Status: statusFail}, report)
+	})
+
+	t.Run("admin verbose", func(t *testing.T) {
+		code, report := serve(AdminHandler(Readiness), "/readyz?verbose")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "abc123", report.Version)
+		assert.Equal(t, statusOK, report.Checks["ok"].Status)
+		assert.Equal(t, "dial tcp 10.0.0.1:5432: refused", report.Checks["broken"].Error)
+	})
+
+	t.Run("admin exclude", func(t *testing.T) {
+		code, report := serve(AdminHandler(Readiness), "/readyz?exclude=broken")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, Report{Status: statusOK}, report)
+	})
+}
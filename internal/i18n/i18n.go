@@ -0,0 +1,111 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package i18n provides the message catalogs of the supported locales.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Locale is a supported locale with its message catalog.
+type Locale struct {
+	// Tag is the language tag of the locale, e.g. "en".
+	Tag      language.Tag
+	messages map[string]string
+}
+
+var (
+	locales []*Locale
+	tags    []language.Tag
+	matcher language.Matcher
+)
+
+func init() {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic("i18n: read locales: " + err.Error())
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		data, err := localeFS.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic("i18n: read locale " + name + ": " + err.Error())
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("i18n: parse locale " + name + ": " + err.Error())
+		}
+
+		tag := language.MustParse(name)
+		locales = append(locales, &Locale{Tag: tag, messages: messages})
+		tags = append(tags, tag)
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Default returns the configured default locale, or the first supported
+// locale if it is not supported.
+func Default() *Locale {
+	tag, err := language.Parse(conf.App.DefaultLocale)
+	if err == nil {
+		for _, locale := range locales {
+			if locale.Tag == tag {
+				return locale
+			}
+		}
+	}
+	return locales[0]
+}
+
+// Match returns the supported locale that best matches the Accept-Language
+// header, or the default locale if none matches.
+func Match(acceptLanguage string) *Locale {
+	wanted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(wanted) == 0 {
+		return Default()
+	}
+	_, index, confidence := matcher.Match(wanted...)
+	if confidence == language.No {
+		return Default()
+	}
+	return locales[index]
+}
+
+// Has reports whether the locale or the default locale has the message.
+func (l *Locale) Has(key string) bool {
+	if _, ok := l.messages[key]; ok {
+		return true
+	}
+	_, ok := Default().messages[key]
+	return ok
+}
+
+// Tr returns the message of the key formatted with the args. It falls back to
+// the message of the default locale, and then the key itself.
+func (l *Locale) Tr(key string, args ...interface{}) string {
+	format, ok := l.messages[key]
+	if !ok {
+		format, ok = Default().messages[key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
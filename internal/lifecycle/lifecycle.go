@@ -0,0 +1,157 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package lifecycle manages the readiness and the ordered shutdown of the server.
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// hook is a phase of the shutdown.
+type hook struct {
+	name    string
+	timeout time.Duration
+	fn      func(ctx context.Context) error
+}
+
+// Manager runs the shutdown phases in the order they are registered, once a
+// signal is received or Stop is called.
+type Manager struct {
+	preStopDelay time.Duration
+
+	mu    sync.Mutex
+	hooks []hook
+
+	ready    atomic.Bool
+	started  atomic.Bool
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	signalCh chan os.Signal
+}
+
+// New returns a new Manager, which waits for the pre-stop delay after being
+// marked not ready, so that the load balancers can stop routing requests to
+// the server before it stops accepting them.
+//
+// The signals are caught from now on instead of killing the process, so New
+// should be called before the server starts.
+func New(preStopDelay time.Duration, signals ...os.Signal) *Manager {
+	signalCh := make(chan os.Signal, 2)
+	// Notify relays all the signals if none is given.
+	if len(signals) > 0 {
+		signal.Notify(signalCh, signals...)
+	}
+	return &Manager{
+		preStopDelay: preStopDelay,
+		stopCh:       make(chan struct{}),
+		signalCh:     signalCh,
+	}
+}
+
+// OnShutdown registers a shutdown phase. The context passed to fn is canceled
+// after the timeout, and has no deadline if the timeout is zero.
+func (m *Manager) OnShutdown(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, timeout: timeout, fn: fn})
+}
+
+// SetReady marks whether the server is ready to serve requests. The server is
+// considered started once it has been marked ready.
+func (m *Manager) SetReady(ready bool) {
+	m.ready.Store(ready)
+	if ready {
+		m.started.Store(true)
+	}
+}
+
+// Ready reports whether the server is ready to serve requests.
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// Started reports whether the server has finished starting up.
+func (m *Manager) Started() bool {
+	return m.started.Load()
+}
+
+// Stop triggers the shutdown, e.g. when the server fails to serve. It is safe
+// to be called multiple times.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+}
+
+// Run blocks until one of the signals passed to New is received or Stop is
+// called, and then runs the shutdown. A second signal during the shutdown
+// forces the process to exit. It returns the errors of the failed phases.
+func (m *Manager) Run() error {
+	defer signal.Stop(m.signalCh)
+
+	select {
+	case sig := <-m.signalCh:
+		logrus.WithField("signal", sig.String()).Info("Received signal, shutting down")
+	case <-m.stopCh:
+		logrus.Info("Shutting down")
+	}
+
+	go func() {
+		sig := <-m.signalCh
+		logrus.WithField("signal", sig.String()).Warn("Received second signal, forcing exit")
+		os.Exit(1)
+	}()
+
+	return m.shutdown()
+}
+
+func (m *Manager) shutdown() error {
+	m.SetReady(false)
+	logrus.Info("Marked not ready")
+
+	if m.preStopDelay > 0 {
+		logrus.WithField("delay", m.preStopDelay.String()).Info("Waiting for the pre-stop delay")
+		time.Sleep(m.preStopDelay)
+	}
+
+	m.mu.Lock()
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	var failed []string
+	for _, h := range hooks {
+		start := time.Now()
+		logger := logrus.WithField("phase", h.name)
+		logger.Info("Shutting down")
+
+		if err := runHook(h); err != nil {
+			logger.WithError(err).Error("Failed to shut down")
+			failed = append(failed, errors.Wrap(err, h.name).Error())
+			continue
+		}
+		logger.WithField("elapsed", time.Since(start).String()).Info("Shut down")
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func runHook(h hook) error {
+	ctx := context.Background()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	return h.fn(ctx)
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lifecycle
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SignalBeforeRun(t *testing.T) {
+	m := New(0, syscall.SIGUSR1)
+	var phases []string
+	m.OnShutdown("first", 0, func(context.Context) error {
+		phases = append(phases, "first")
+		return nil
+	})
+	m.OnShutdown("second", 0, func(context.Context) error {
+		phases = append(phases, "second")
+		return nil
+	})
+	m.SetReady(true)
+
+	// The signal is caught by New even though Run hasn't been called yet.
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.NoError(t, m.Run())
+	assert.Equal(t, []string{"first", "second"}, phases)
+	assert.False(t, m.Ready())
+	assert.True(t, m.Started())
+}
+
+func TestManager_Stop(t *testing.T) {
+	m := New(0)
+	m.OnShutdown("failing", 0, func(context.Context) error { return errors.New("boom") })
+	m.OnShutdown("ok", 0, func(context.Context) error { return nil })
+	m.OnShutdown("timeout", 1, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	m.Stop()
+	m.Stop()
+	err := m.Run()
+	require.Error(t, err)
+	assert.Equal(t, "failing: boom; timeout: context deadline exceeded", err.Error())
+}
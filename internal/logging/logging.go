@@ -0,0 +1,93 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package logging
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// Supported log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Init sets up the standard logger with the configured level and format, and
+// the hook to inject the fields of the context into the entries.
+func Init() error {
+	level, err := logrus.ParseLevel(conf.Log.Level)
+	if err != nil {
+		return errors.Wrap(err, "parse level")
+	}
+
+	var formatter logrus.Formatter
+	switch conf.Log.Format {
+	case FormatText:
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	default:
+		return errors.Errorf("unsupported format %q", conf.Log.Format)
+	}
+
+	logrus.SetOutput(os.Stdout)
+	logrus.SetLevel(level)
+	logrus.SetFormatter(formatter)
+	logrus.AddHook(contextHook{})
+	return nil
+}
+
+type fieldsKey struct{}
+
+// WithField returns a copy of the context carrying the field, which is added
+// to the entries logged with the context.
+func WithField(ctx context.Context, key string, value interface{}) context.Context {
+	fields := fieldsFromContext(ctx)
+	newFields := make(logrus.Fields, len(fields)+1)
+	for k, v := range fields {
+		newFields[k] = v
+	}
+	newFields[key] = value
+	return context.WithValue(ctx, fieldsKey{}, newFields)
+}
+
+func fieldsFromContext(ctx context.Context) logrus.Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// contextHook injects the trace and span IDs, and the fields set by WithField
+// from the context of the entry.
+type contextHook struct{}
+
+func (contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (contextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	for k, v := range fieldsFromContext(entry.Context) {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if spanContext.IsValid() {
+		entry.Data["trace_id"] = spanContext.TraceID().String()
+		entry.Data["span_id"] = spanContext.SpanID().String()
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/wuhan005/go-template/internal/appconst"
+)
+
+// Registry is the Prometheus registry of the application metrics. It contains
+// the Go runtime, process and build info metrics by default, and the
+// OpenTelemetry metrics once the SDK is set up.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "go_template_build_info",
+			Help: "A metric with a constant '1' value labeled by the build commit, date and Go version.",
+			ConstLabels: prometheus.Labels{
+				"commit":     appconst.BuildCommit,
+				"date":       appconst.BuildDate,
+				"go_version": runtime.Version(),
+			},
+		}, func() float64 { return 1 }),
+	)
+}
+
+// RegisterDB registers the connection pool stats of the database.
+func RegisterDB(db *sql.DB, name string) error {
+	if err := Registry.Register(collectors.NewDBStatsCollector(db, name)); err != nil {
+		return errors.Wrap(err, "register database stats collector")
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler to serve the metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,311 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// lockID is the key of the Postgres advisory lock held while migrating,
+// which makes sure only one instance migrates the database at a time.
+const lockID = 4_617_002_531
+
+// Migration is a versioned database schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status represents the state of a migration in the database.
+type Status struct {
+	Migration
+	// AppliedAt is nil if the migration has not been applied yet.
+	AppliedAt *time.Time
+}
+
+// Migrator applies the embedded migrations to the database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New returns a Migrator for the given database connection with all the
+// embedded migrations loaded.
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := load(migrationFS)
+	if err != nil {
+		return nil, errors.Wrap(err, "load migrations")
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// load reads the migrations from the given file system. The file names must be
+// in the format of `<version>_<name>.(up|down).sql`.
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, errors.Wrap(err, "glob")
+	}
+
+	migrations := make(map[int64]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+		name, direction, ok := strings.Cut(strings.TrimSuffix(base, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, errors.Errorf("unexpected migration file name %q", base)
+		}
+		versionStr, name, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, errors.Errorf("unexpected migration file name %q", base)
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse version of %q", base)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %q", base)
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrations[version] = m
+		} else if m.Name != name {
+			return nil, errors.Errorf("duplicate migration version %d", version)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Up == "" {
+			return nil, errors.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// withLock runs the given function on a dedicated connection which holds the
+// migration advisory lock, creating the `schema_migrations` table if needed.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get connection")
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return errors.Wrap(err, "acquire lock")
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx has been cancelled.
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version    BIGINT PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`); err != nil {
+		return errors.Wrap(err, "create schema_migrations table")
+	}
+	return fn(conn)
+}
+
+// queryer is implemented by both *sql.DB and *sql.Conn.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// applied returns the applied time of the migrations keyed by version.
+func applied(ctx context.Context, conn queryer) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, errors.Wrap(err, "query")
+	}
+	defer func() { _ = rows.Close() }()
+
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Wrap(err, "scan")
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+// run executes the migration SQL and records the change in a single transaction.
+func run(ctx context.Context, conn *sql.Conn, query, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		// Surface the hint raised by the migration, e.g. how to resolve the data
+		// conflicting with it.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Hint != "" {
+			return errors.Wrapf(err, "exec (hint: %s)", pgErr.Hint)
+		}
+		return errors.Wrap(err, "exec")
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return errors.Wrap(err, "record")
+	}
+	return tx.Commit()
+}
+
+// Up applies all the pending migrations in order, returning the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := applied(ctx, conn)
+		if err != nil {
+			return errors.Wrap(err, "get applied migrations")
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if err := run(ctx, conn, migration.Up,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name,
+			); err != nil {
+				return errors.Wrapf(err, "apply %d_%s", migration.Version, migration.Name)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the given number of the latest applied migrations, returning
+// the reverted ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := applied(ctx, conn)
+		if err != nil {
+			return errors.Wrap(err, "get applied migrations")
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return errors.Errorf("migration %d_%s is irreversible", migration.Version, migration.Name)
+			}
+			if err := run(ctx, conn, migration.Down,
+				"DELETE FROM schema_migrations WHERE version = $1", migration.Version,
+			); err != nil {
+				return errors.Wrapf(err, "revert %d_%s", migration.Version, migration.Name)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status returns the state of all the known migrations in order. Like Check,
+// it neither waits for the migration lock nor creates the schema_migrations
+// table, so that it works with the read-only roles.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, errors.Wrap(err, "check schema_migrations table")
+	}
+
+	versions := make(map[int64]time.Time)
+	if exists {
+		var err error
+		versions, err = applied(ctx, m.db)
+		if err != nil {
+			return nil, errors.Wrap(err, "get applied migrations")
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Check returns an error if any of the migrations has not been applied. It
+// doesn't wait for the migration lock, so that it can be used by the health
+// checks while another instance is migrating.
+func (m *Migrator) Check(ctx context.Context) error {
+	var count int64
+	versions := make([]int64, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		versions = append(versions, migration.Version)
+	}
+	err := m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version = ANY($1)", versions).Scan(&count)
+	if err != nil {
+		return errors.Wrap(err, "count applied migrations")
+	}
+	if pending := int64(len(m.migrations)) - count; pending > 0 {
+		return errors.Errorf("database schema is behind by %d migration(s)", pending)
+	}
+	return nil
+}
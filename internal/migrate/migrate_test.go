@@ -0,0 +1,136 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wuhan005/go-template/internal/dbtest"
+)
+
+// tableExists reports whether the table exists in the database.
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var exists bool
+	require.NoError(t, db.QueryRow("SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists))
+	return exists
+}
+
+func TestMigrator(t *testing.T) {
+	db := dbtest.Open(t, dbtest.NewDatabase(t))
+	migrator, err := New(db)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrator.migrations)
+	ctx := context.Background()
+	total := len(migrator.migrations)
+
+	t.Run("status of empty database", func(t *testing.T) {
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, total)
+		for _, status := range statuses {
+			assert.Nil(t, status.AppliedAt, "migration %d", status.Version)
+		}
+
+		pending, err := migrator.Pending(ctx)
+		require.NoError(t, err)
+		assert.Len(t, pending, total)
+
+		// Reading the status doesn't change the database.
+		assert.False(t, tableExists(t, db, "schema_migrations"))
+		assert.Error(t, migrator.Check(ctx))
+	})
+
+	t.Run("up", func(t *testing.T) {
+		done, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Len(t, done, total)
+		assert.True(t, tableExists(t, db, "users"))
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt, "migration %d", status.Version)
+		}
+		assert.NoError(t, migrator.Check(ctx))
+
+		done, err = migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, done)
+	})
+
+	t.Run("down", func(t *testing.T) {
+		done, err := migrator.Down(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, done, 1)
+		latest := migrator.migrations[total-1]
+		assert.Equal(t, latest.Version, done[0].Version)
+
+		pending, err := migrator.Pending(ctx)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, latest.Version, pending[0].Version)
+		assert.EqualError(t, migrator.Check(ctx), "database schema is behind by 1 migration(s)")
+
+		done, err = migrator.Up(ctx)
+		require.NoError(t, err)
+		require.Len(t, done, 1)
+		assert.Equal(t, latest.Version, done[0].Version)
+	})
+
+	t.Run("status doesn't wait for the lock", func(t *testing.T) {
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID)
+		require.NoError(t, err)
+		defer func() { _, _ = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID) }()
+
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_, err = migrator.Status(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, migrator.Check(ctx))
+	})
+
+	t.Run("down to empty", func(t *testing.T) {
+		done, err := migrator.Down(ctx, total+1)
+		require.NoError(t, err)
+		assert.Len(t, done, total)
+		assert.False(t, tableExists(t, db, "users"))
+
+		pending, err := migrator.Pending(ctx)
+		require.NoError(t, err)
+		assert.Len(t, pending, total)
+	})
+}
+
+func TestMigrator_DuplicateEmails(t *testing.T) {
+	db := dbtest.Open(t, dbtest.NewDatabase(t))
+	migrator, err := New(db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Stop before the unique index of the emails.
+	migrator.migrations = migrator.migrations[:1]
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO users (uid, email) VALUES ('1', 'Alice@example.com'), ('2', 'alice@example.com'), ('3', 'bob@example.com')`)
+	require.NoError(t, err)
+
+	migrator, err = New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple accounts share the emails case-insensitively: alice@example.com")
+	assert.NotContains(t, err.Error(), "bob@example.com")
+	assert.Contains(t, err.Error(), "hint: Keep one account of each email")
+}
@@ -0,0 +1,31 @@
+-- The emails differing only in case were allowed before, which must be resolved
+-- before creating the index. The conflicting emails are reported instead of the
+-- bare unique violation. To keep the oldest account of each email and
+-- soft-delete the others, run:
+--
+--   UPDATE users u SET deleted_at = NOW()
+--   WHERE deleted_at IS NULL AND EXISTS (
+--       SELECT 1 FROM users o
+--       WHERE o.deleted_at IS NULL AND LOWER(o.email) = LOWER(u.email) AND o.id < u.id
+--   );
+DO
+$$
+    DECLARE
+        duplicates TEXT;
+    BEGIN
+        SELECT string_agg(email, ', ' ORDER BY email)
+        INTO duplicates
+        FROM (SELECT LOWER(email) AS email
+              FROM users
+              WHERE deleted_at IS NULL
+              GROUP BY LOWER(email)
+              HAVING COUNT(*) > 1) AS conflicts;
+
+        IF duplicates IS NOT NULL THEN
+            RAISE EXCEPTION 'multiple accounts share the emails case-insensitively: %', duplicates
+                USING HINT = 'Keep one account of each email and change or delete the others, see 0002_users_email_unique.up.sql';
+        END IF;
+    END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique ON users (LOWER(email)) WHERE deleted_at IS NULL;
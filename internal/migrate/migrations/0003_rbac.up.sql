@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS roles
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id    BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission TEXT   NOT NULL,
+    PRIMARY KEY (role_id, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles
+(
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
+
+-- The admin role is granted all the permissions.
+INSERT INTO roles (name)
+VALUES ('admin')
+ON CONFLICT DO NOTHING;
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, '*'
+FROM roles
+WHERE name = 'admin'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- The failed sign-in attempts are tracked by keys of hashed emails ("email:<sha256>")
+-- and client IPs ("ip:<address>").
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    key            TEXT PRIMARY KEY,
+    failures       INTEGER     NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMPTZ NOT NULL,
+    locked_until   TIMESTAMPTZ
+);
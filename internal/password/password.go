@@ -0,0 +1,214 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/thanhpk/randstr"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// Supported hashing algorithms.
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+	Scrypt   = "scrypt"
+)
+
+const (
+	saltLength = 16
+	keyLength  = 32
+)
+
+// ErrUnknownFormat is returned when the hash is not in a supported PHC format.
+var ErrUnknownFormat = errors.New("unknown password hash format")
+
+var b64 = base64.RawStdEncoding
+
+// IsPHC reports whether the given hash is in the PHC string format, e.g.
+// "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>".
+func IsPHC(hash string) bool {
+	return strings.HasPrefix(hash, "$")
+}
+
+// peppered returns the password mixed with the server-side pepper if it is configured.
+func peppered(password string) []byte {
+	if conf.Password.Pepper == "" {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, []byte(conf.Password.Pepper))
+	mac.Write([]byte(password))
+	// Hex encoding keeps the input within the 72 bytes limit of bcrypt.
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Hash hashes the password with the configured algorithm and parameters, and
+// returns the hash in the PHC string format.
+func Hash(password string) (string, error) {
+	input := peppered(password)
+	salt := randstr.Bytes(saltLength)
+
+	switch conf.Password.Algorithm {
+	case Argon2id:
+		p := conf.Password
+		key := argon2.IDKey(input, salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, keyLength)
+		return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			Argon2id, argon2.Version, p.Argon2Memory, p.Argon2Time, p.Argon2Threads, b64.EncodeToString(salt), b64.EncodeToString(key),
+		), nil
+
+	case Bcrypt:
+		hash, err := bcrypt.GenerateFromPassword(input, conf.Password.BcryptCost)
+		if err != nil {
+			return "", errors.Wrap(err, "bcrypt")
+		}
+		return string(hash), nil
+
+	case Scrypt:
+		p := conf.Password
+		key, err := scrypt.Key(input, salt, 1<<p.ScryptLogN, p.ScryptR, p.ScryptP, keyLength)
+		if err != nil {
+			return "", errors.Wrap(err, "scrypt")
+		}
+		return fmt.Sprintf("$%s$ln=%d,r=%d,p=%d$%s$%s",
+			Scrypt, p.ScryptLogN, p.ScryptR, p.ScryptP, b64.EncodeToString(salt), b64.EncodeToString(key),
+		), nil
+
+	default:
+		return "", errors.Errorf("unsupported algorithm %q", conf.Password.Algorithm)
+	}
+}
+
+// Verify checks if the password matches the PHC formatted hash. The returned
+// needsRehash reports whether the hash was not produced with the currently
+// configured algorithm and parameters, and should be upgraded.
+func Verify(password, hash string) (ok, needsRehash bool, err error) {
+	input := peppered(password)
+
+	if strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$") {
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), input); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, false, nil
+			}
+			return false, false, errors.Wrap(err, "bcrypt")
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return false, false, errors.Wrap(err, "bcrypt cost")
+		}
+		return true, conf.Password.Algorithm != Bcrypt || cost != conf.Password.BcryptCost, nil
+	}
+
+	parts := strings.Split(hash, "$")
+	switch {
+	case len(parts) == 6 && parts[1] == Argon2id:
+		var version int
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return false, false, ErrUnknownFormat
+		}
+		params, err := parseParams(parts[3])
+		if err != nil {
+			return false, false, err
+		}
+		salt, key, err := decodeSaltAndKey(parts[4], parts[5])
+		if err != nil {
+			return false, false, err
+		}
+
+		memory, time, threads := uint32(params["m"]), uint32(params["t"]), uint8(params["p"])
+		if memory == 0 || time == 0 || threads == 0 {
+			return false, false, ErrUnknownFormat
+		}
+		computed := argon2.IDKey(input, salt, time, memory, threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, computed) != 1 {
+			return false, false, nil
+		}
+		c := conf.Password
+		return true, c.Algorithm != Argon2id || memory != c.Argon2Memory || time != c.Argon2Time || threads != c.Argon2Threads, nil
+
+	case len(parts) == 5 && parts[1] == Scrypt:
+		params, err := parseParams(parts[2])
+		if err != nil {
+			return false, false, err
+		}
+		salt, key, err := decodeSaltAndKey(parts[3], parts[4])
+		if err != nil {
+			return false, false, err
+		}
+
+		logN, r, p := params["ln"], params["r"], params["p"]
+		if logN <= 0 || logN >= 63 || r <= 0 || p <= 0 {
+			return false, false, ErrUnknownFormat
+		}
+		computed, err := scrypt.Key(input, salt, 1<<logN, r, p, len(key))
+		if err != nil {
+			return false, false, errors.Wrap(err, "scrypt")
+		}
+		if subtle.ConstantTimeCompare(key, computed) != 1 {
+			return false, false, nil
+		}
+		c := conf.Password
+		return true, c.Algorithm != Scrypt || logN != c.ScryptLogN || r != c.ScryptR || p != c.ScryptP, nil
+	}
+	return false, false, ErrUnknownFormat
+}
+
+// parseParams parses the comma-separated "key=value" parameters of a PHC string.
+func parseParams(s string) (map[string]int, error) {
+	params := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, ErrUnknownFormat
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil || v < 0 {
+			return nil, ErrUnknownFormat
+		}
+		params[key] = v
+	}
+	return params, nil
+}
+
+func decodeSaltAndKey(saltStr, keyStr string) (salt, key []byte, err error) {
+	salt, err = b64.DecodeString(saltStr)
+	if err != nil {
+		return nil, nil, ErrUnknownFormat
+	}
+	key, err = b64.DecodeString(keyStr)
+	if err != nil || len(key) == 0 {
+		return nil, nil, ErrUnknownFormat
+	}
+	return salt, key, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// VerifyDummy verifies the password against a dummy hash produced with the
+// configured algorithm and parameters. It is used when there is no hash to
+// verify, e.g. signing in with an unknown email, so that it takes as long as
+// verifying a real one.
+func VerifyDummy(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = Hash(randstr.String(16))
+	})
+	_, _, _ = Verify(password, dummyHash)
+}
@@ -0,0 +1,207 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// setConfig sets the cheapest parameters of all the algorithms, and the given
+// algorithm and pepper for the test.
+func setConfig(t *testing.T, algorithm, pepper string) {
+	t.Helper()
+	saved := conf.Password
+	t.Cleanup(func() { conf.Password = saved })
+
+	conf.Password.Algorithm = algorithm
+	conf.Password.Pepper = pepper
+	conf.Password.Argon2Memory = 64
+	conf.Password.Argon2Time = 1
+	conf.Password.Argon2Threads = 1
+	conf.Password.BcryptCost = bcrypt.MinCost
+	conf.Password.ScryptLogN = 4
+	conf.Password.ScryptR = 8
+	conf.Password.ScryptP = 1
+}
+
+func TestHashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{Argon2id, Bcrypt, Scrypt} {
+		for _, pepper := range []string{"", "pepper"} {
+			t.Run(algorithm+"/pepper="+pepper, func(t *testing.T) {
+				setConfig(t, algorithm, pepper)
+
+				hash, err := Hash("correct horse")
+				require.NoError(t, err)
+				assert.True(t, IsPHC(hash))
+
+				other, err := Hash("correct horse")
+				require.NoError(t, err)
+				assert.NotEqual(t, hash, other, "hashes should be salted")
+
+				ok, needsRehash, err := Verify("correct horse", hash)
+				require.NoError(t, err)
+				assert.True(t, ok)
+				assert.False(t, needsRehash)
+
+				ok, _, err = Verify("wrong horse", hash)
+				require.NoError(t, err)
+				assert.False(t, ok)
+			})
+		}
+	}
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		setConfig(t, "md5", "")
+		_, err := Hash("correct horse")
+		assert.Error(t, err)
+	})
+}
+
+func TestVerify_Pepper(t *testing.T) {
+	setConfig(t, Argon2id, "pepper")
+	peppered, err := Hash("correct horse")
+	require.NoError(t, err)
+
+	setConfig(t, Argon2id, "")
+	plain, err := Hash("correct horse")
+	require.NoError(t, err)
+
+	ok, _, err := Verify("correct horse", peppered)
+	require.NoError(t, err)
+	assert.False(t, ok, "peppered hash should not match without the pepper")
+
+	setConfig(t, Argon2id, "another pepper")
+	ok, _, err = Verify("correct horse", peppered)
+	require.NoError(t, err)
+	assert.False(t, ok, "peppered hash should not match with another pepper")
+	ok, _, err = Verify("correct horse", plain)
+	require.NoError(t, err)
+	assert.False(t, ok, "plain hash should not match with a pepper")
+
+	// Bcrypt only takes 72 bytes, which the peppered input must fit in.
+	setConfig(t, Bcrypt, "pepper")
+	long := strings.Repeat("a", 100)
+	hash, err := Hash(long)
+	require.NoError(t, err)
+	ok, _, err = Verify(long[:72]+"b", hash)
+	require.NoError(t, err)
+	assert.False(t, ok, "bytes after the 72nd should not be ignored")
+}
+
+func TestVerify_NeedsRehash(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		change    func()
+	}{
+		{name: "argon2id algorithm", algorithm: Argon2id, change: func() { conf.Password.Algorithm = Bcrypt }},
+		{name: "argon2id memory", algorithm: Argon2id, change: func() { conf.Password.Argon2Memory = 128 }},
+		{name: "argon2id time", algorithm: Argon2id, change: func() { conf.Password.Argon2Time = 2 }},
+		{name: "argon2id threads", algorithm: Argon2id, change: func() { conf.Password.Argon2Threads = 2 }},
+		{name: "bcrypt algorithm", algorithm: Bcrypt, change: func() { conf.Password.Algorithm = Scrypt }},
+		{name: "bcrypt cost", algorithm: Bcrypt, change: func() { conf.Password.BcryptCost = bcrypt.MinCost + 1 }},
+		{name: "scrypt algorithm", algorithm: Scrypt, change: func() { conf.Password.Algorithm = Argon2id }},
+		{name: "scrypt N", algorithm: Scrypt, change: func() { conf.Password.ScryptLogN = 5 }},
+		{name: "scrypt r", algorithm: Scrypt, change: func() { conf.Password.ScryptR = 4 }},
+		{name: "scrypt p", algorithm: Scrypt, change: func() { conf.Password.ScryptP = 2 }},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setConfig(t, tc.algorithm, "")
+			hash, err := Hash("correct horse")
+			require.NoError(t, err)
+
+			tc.change()
+			ok, needsRehash, err := Verify("correct horse", hash)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.True(t, needsRehash)
+		})
+	}
+}
+
+func TestVerify_LegacyBcrypt(t *testing.T) {
+	setConfig(t, Argon2id, "")
+
+	// The hashes produced by other bcrypt implementations, e.g. "$2y$" of PHP.
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(hash), "$2a$"))
+
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		t.Run(prefix, func(t *testing.T) {
+			legacy := prefix + strings.TrimPrefix(string(hash), "$2a$")
+
+			ok, needsRehash, err := Verify("correct horse", legacy)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.True(t, needsRehash, "legacy bcrypt hashes should be upgraded to argon2id")
+
+			ok, _, err = Verify("wrong horse", legacy)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		setConfig(t, Bcrypt, "")
+		ok, needsRehash, err := Verify("correct horse", string(hash))
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, needsRehash)
+	})
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	setConfig(t, Argon2id, "")
+
+	for _, hash := range []string{
+		"",
+		"plain",
+		"$",
+		"$md5$abc",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=x$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=-1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64;t=1;p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$",
+		"$scrypt$ln=4,r=8,p=1$c2FsdHNhbHQ",
+		"$scrypt$ln=4,r=8$c2FsdHNhbHQ$a2V5a2V5",
+		"$scrypt$ln=0,r=8,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$scrypt$ln=64,r=8,p=1$c2FsdHNhbHQ$a2V5a2V5",
+		"$scrypt$ln=4,r=8,p=1$c2FsdHNhbHQ$!!!",
+	} {
+		t.Run(hash, func(t *testing.T) {
+			ok, needsRehash, err := Verify("correct horse", hash)
+			assert.ErrorIs(t, err, ErrUnknownFormat)
+			assert.False(t, ok)
+			assert.False(t, needsRehash)
+		})
+	}
+
+	t.Run("corrupted bcrypt", func(t *testing.T) {
+		ok, _, err := Verify("correct horse", "$2a$04$tooshort")
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestVerifyDummy(t *testing.T) {
+	setConfig(t, Bcrypt, "")
+	assert.NotPanics(t, func() { VerifyDummy("correct horse") })
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Limiter = (*memory)(nil)
+
+// sweepInterval is the interval to remove the expired buckets.
+const sweepInterval = time.Minute
+
+// NewMemoryLimiter returns a Limiter that keeps the buckets in memory, which
+// is only suitable for a single instance.
+func NewMemoryLimiter() Limiter {
+	return &memory{
+		buckets:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+type memory struct {
+	mu        sync.Mutex
+	buckets   map[string]time.Time // key -> TAT
+	lastSweep time.Time
+}
+
+func (m *memory) Allow(_ context.Context, key string, policy Policy) (*Result, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The buckets whose TAT has passed are full, which are the same as absent.
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, tat := range m.buckets {
+			if tat.Before(now) {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	result, tat := gcra(now, m.buckets[key], policy)
+	if result.Allowed {
+		m.buckets[key] = tat
+	}
+	return result, nil
+}
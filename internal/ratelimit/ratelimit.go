@@ -0,0 +1,128 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package ratelimit implements token bucket rate limiting with the generic
+// cell rate algorithm (GCRA), which only stores a timestamp per key.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Policy is the rate limiting policy. It allows Limit requests per Period on
+// average, and up to Burst requests at once.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+	// Burst is the capacity of the bucket, defaults to Limit.
+	Burst int
+}
+
+// PerSecond returns the policy that allows n requests per second.
+func PerSecond(n int) Policy {
+	return Policy{Limit: n, Period: time.Second}
+}
+
+// PerMinute returns the policy that allows n requests per minute.
+func PerMinute(n int) Policy {
+	return Policy{Limit: n, Period: time.Minute}
+}
+
+// PerHour returns the policy that allows n requests per hour.
+func PerHour(n int) Policy {
+	return Policy{Limit: n, Period: time.Hour}
+}
+
+func (p Policy) burst() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// emissionInterval returns the interval to refill a token.
+func (p Policy) emissionInterval() time.Duration {
+	return p.Period / time.Duration(p.Limit)
+}
+
+// String returns the policy in the format of the RateLimit-Policy header,
+// e.g. "10;w=60".
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(p.Period.Seconds()))
+}
+
+// Result is the result of a rate limiting check.
+type Result struct {
+	// Allowed reports whether the request is allowed.
+	Allowed bool
+	// Limit is the capacity of the bucket.
+	Limit int
+	// Remaining is the number of requests allowed before being limited.
+	Remaining int
+	// RetryAfter is the time to wait before the request is allowed, which is
+	// zero if the request is allowed.
+	RetryAfter time.Duration
+	// ResetAfter is the time for the bucket to be full again.
+	ResetAfter time.Duration
+}
+
+// Limiter checks if the requests of the keys exceed the policies.
+type Limiter interface {
+	// Allow takes a token from the bucket of the key if there is any.
+	Allow(ctx context.Context, key string, policy Policy) (*Result, error)
+}
+
+// gcra computes the result of the request at now, given the theoretical
+// arrival time (TAT) of the bucket. The returned TAT should be stored if the
+// request is allowed.
+func gcra(now, tat time.Time, policy Policy) (*Result, time.Time) {
+	interval := policy.emissionInterval()
+	tolerance := interval * time.Duration(policy.burst())
+
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-tolerance)
+
+	result := &Result{Limit: policy.burst()}
+	diff := now.Sub(allowAt)
+	if diff < 0 {
+		result.RetryAfter = -diff
+		result.ResetAfter = tat.Sub(now)
+		return result, tat
+	}
+
+	result.Allowed = true
+	result.Remaining = int(diff / interval)
+	result.ResetAfter = newTAT.Sub(now)
+	return result, newTAT
+}
+
+// Supported backends.
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// New returns the Limiter of the backend. The Redis client is required by the
+// redis backend.
+func New(backend string, client *goredis.Client) (Limiter, error) {
+	switch backend {
+	case BackendMemory:
+		return NewMemoryLimiter(), nil
+	case BackendRedis:
+		if client == nil {
+			return nil, errors.New("redis backend requires the Redis address")
+		}
+		return NewRedisLimiter(client), nil
+	default:
+		return nil, errors.Errorf("unsupported backend %q", backend)
+	}
+}
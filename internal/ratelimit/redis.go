@@ -0,0 +1,71 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var _ Limiter = (*redis)(nil)
+
+// keyPrefix is the prefix of the Redis keys of the buckets.
+const keyPrefix = "ratelimit:"
+
+// gcraScript runs GCRA atomically with the clock of Redis, so the instances
+// don't have to agree on the time. The TAT is stored in microseconds, and
+// expires once the bucket is full again.
+var gcraScript = goredis.NewScript(`
+local key = KEYS[1]
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local diff = now - (new_tat - interval * burst)
+if diff < 0 then
+  return {0, 0, -diff, tat - now}
+end
+
+redis.call("SET", key, new_tat, "PX", math.ceil((new_tat - now) / 1000))
+return {1, math.floor(diff / interval), 0, new_tat - now}
+`)
+
+// NewRedisLimiter returns a Limiter that keeps the buckets in Redis, which
+// are shared between the instances.
+func NewRedisLimiter(client *goredis.Client) Limiter {
+	return &redis{client: client}
+}
+
+type redis struct {
+	client *goredis.Client
+}
+
+func (r *redis) Allow(ctx context.Context, key string, policy Policy) (*Result, error) {
+	values, err := gcraScript.Run(ctx, r.client, []string{keyPrefix + key},
+		policy.emissionInterval().Microseconds(), policy.burst(),
+	).Int64Slice()
+	if err != nil {
+		return nil, errors.Wrap(err, "run script")
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.burst(),
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
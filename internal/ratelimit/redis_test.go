@@ -0,0 +1,69 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	limiter := NewRedisLimiter(client)
+
+	ctx := context.Background()
+	policy := PerMinute(3)
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "key", policy)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "key", policy)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.ResetAfter)
+
+	// The other keys have their own buckets.
+	result, err = limiter.Allow(ctx, "other", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// A request is allowed again once an emission interval has passed.
+	server.SetTime(now.Add(20 * time.Second))
+	result, err = limiter.Allow(ctx, "key", policy)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	assert.True(t, server.Exists(keyPrefix+"key"))
+	ttl := server.TTL(keyPrefix + "key")
+	assert.Equal(t, time.Minute, ttl)
+}
+
+func TestRedisLimiter_Unavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	server.Close()
+
+	_, err := NewRedisLimiter(client).Allow(context.Background(), "key", PerMinute(3))
+	assert.Error(t, err)
+}
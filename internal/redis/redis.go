@@ -0,0 +1,53 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+var clientInstance *goredis.Client
+
+// Init initializes the Redis client.
+func Init() (*goredis.Client, error) {
+	client := goredis.NewClient(&goredis.Options{
+		Addr:     conf.Redis.Address,
+		Username: conf.Redis.Username,
+		Password: conf.Redis.Password,
+		DB:       conf.Redis.Database,
+	})
+
+	if err := redisotel.InstrumentTracing(client,
+		redisotel.WithAttributes(
+			attribute.String("db.ip", conf.Redis.Address),
+		),
+	); err != nil {
+		return nil, errors.Wrap(err, "instrument tracing")
+	}
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, errors.Wrap(err, "ping")
+	}
+
+	clientInstance = client
+
+	return client, nil
+}
+
+// Ping checks the Redis connection.
+func Ping(ctx context.Context) error {
+	if err := clientInstance.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
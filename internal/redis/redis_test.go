@@ -0,0 +1,105 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/health"
+)
+
+// setup points the configuration to a new in-process Redis server.
+func setup(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	conf.Redis.Address = server.Addr()
+	conf.Redis.Username = ""
+	conf.Redis.Password = ""
+	conf.Redis.Database = 0
+	t.Cleanup(func() { clientInstance = nil })
+	return server
+}
+
+func TestInit(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		server := setup(t)
+		conf.Redis.Database = 2
+
+		client, err := Init()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = client.Close() })
+
+		ctx := context.Background()
+		require.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+		server.Select(2)
+		got, err := server.Get("key")
+		require.NoError(t, err)
+		assert.Equal(t, "value", got)
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		server := setup(t)
+		server.RequireUserAuth("user", "secret")
+
+		conf.Redis.Username = "user"
+		conf.Redis.Password = "wrong"
+		_, err := Init()
+		assert.Error(t, err)
+
+		conf.Redis.Password = "secret"
+		client, err := Init()
+		require.NoError(t, err)
+		_ = client.Close()
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := setup(t)
+		server.Close()
+
+		_, err := Init()
+		assert.Error(t, err)
+		assert.Nil(t, clientInstance)
+	})
+}
+
+func TestPing(t *testing.T) {
+	server := setup(t)
+	client, err := Init()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	assert.NoError(t, Ping(ctx))
+
+	server.Close()
+	assert.Error(t, Ping(ctx))
+}
+
+func TestHealthCheck(t *testing.T) {
+	server := setup(t)
+	client, err := Init()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	health.Register("redis", 0, Ping, health.Readiness)
+
+	ctx := context.Background()
+	report := health.Run(ctx, health.Readiness)
+	assert.True(t, report.Healthy())
+	assert.Equal(t, "ok", report.Checks["redis"].Status)
+
+	server.Close()
+	report = health.Run(ctx, health.Readiness)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, "fail", report.Checks["redis"].Status)
+	assert.NotEmpty(t, report.Checks["redis"].Error)
+}
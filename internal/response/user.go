@@ -40,4 +40,7 @@ func ConvertUsers(users []*db.User) []*User {
 type ListUser struct {
 	Data  []*User `json:"data"`
 	Total int64   `json:"total"`
+	// Next and Prev are the cursors of the adjacent pages in cursor pagination mode.
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
 }
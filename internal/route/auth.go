@@ -0,0 +1,92 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package route
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/context"
+	"github.com/wuhan005/go-template/internal/db"
+	"github.com/wuhan005/go-template/internal/form"
+	"github.com/wuhan005/go-template/internal/response"
+)
+
+// AuthHandler is a struct that handles authentication-related routes.
+type AuthHandler struct{}
+
+// NewAuthHandler creates a new AuthHandler instance.
+func NewAuthHandler() *AuthHandler {
+	return &AuthHandler{}
+}
+
+// Login
+// @Summary Sign in with email and password
+// @Accept json
+// @Produce json
+// @Param form body form.Login true "Login form"
+// @Success 200 {object} response.User
+// @Failure 401 "Invalid email or password" string
+// @Failure 429 "Too many requests or failed sign-in attempts" string
+// @Failure 500 "Internal server error" string
+// @Router /auth/login [post]
+func (*AuthHandler) Login(ctx context.Context, f form.Login) error {
+	user, err := db.Users.Authenticate(ctx.Request().Context(), db.AuthenticateOptions{
+		Email:    f.Email,
+		Password: f.Password,
+		IP:       ctx.IP(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "authenticate user")
+	}
+
+	session, token, err := db.Sessions.Create(ctx.Request().Context(), db.CreateSessionOptions{
+		UserID: user.ID,
+		MaxAge: conf.Session.MaxAge,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create session")
+	}
+
+	ctx.SetCookie(http.Cookie{
+		Name:     conf.Session.CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   conf.Session.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	responseUser := response.ConvertUser(user)
+	return ctx.Success(responseUser)
+}
+
+// Logout
+// @Summary Sign out the current user
+// @Produce json
+// @Success 200 "Signed out successfully" string
+// @Failure 401 "Unauthorized" string
+// @Failure 500 "Internal server error" string
+// @Router /auth/logout [post]
+func (*AuthHandler) Logout(ctx context.Context) error {
+	token := ctx.Cookie(conf.Session.CookieName)
+	if err := db.Sessions.DeleteByToken(ctx.Request().Context(), token); err != nil {
+		return errors.Wrap(err, "delete session")
+	}
+
+	ctx.SetCookie(http.Cookie{
+		Name:     conf.Session.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   conf.Session.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return ctx.Success(ctx.Tr("auth.signed_out"))
+}
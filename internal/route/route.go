@@ -6,45 +6,73 @@ package route
 
 import (
 	gocontext "context"
-	"time"
 
-	"github.com/MEDIGO/go-healthz"
 	flamegoswagger "github.com/asjdf/flamego-swagger"
 	"github.com/flamego/flamego"
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
 	swaggerfiles "github.com/swaggo/files"
 	"gorm.io/gorm"
 
 	_ "github.com/wuhan005/go-template/docs"
 	"github.com/wuhan005/go-template/internal/appconst"
+	"github.com/wuhan005/go-template/internal/conf"
 	"github.com/wuhan005/go-template/internal/context"
 	dbpkg "github.com/wuhan005/go-template/internal/db"
 	"github.com/wuhan005/go-template/internal/form"
+	"github.com/wuhan005/go-template/internal/health"
+	"github.com/wuhan005/go-template/internal/lifecycle"
+	"github.com/wuhan005/go-template/internal/metrics"
+	"github.com/wuhan005/go-template/internal/ratelimit"
+	"github.com/wuhan005/go-template/internal/redis"
 	"github.com/wuhan005/go-template/internal/tracing"
 )
 
 // New creates a new Flamego instance with the necessary middleware and routes.
+// The rate limits are disabled if the limiter is nil, and /readyz reports
+// not ready once the lifecycle manager starts shutting down.
 // @Title Go Template API
 // @Version 1.0
 // @BasePath /api
-func New(db *gorm.DB) *flamego.Flame {
-	f := flamego.Classic()
+func New(db *gorm.DB, redisClient *goredis.Client, limiter ratelimit.Limiter, lc *lifecycle.Manager) *flamego.Flame {
+	f := flamego.New()
+	f.Map(context.ReturnHandler())
 
 	f.Use(
+		flamego.Recovery(),
+		flamego.Static(),
 		tracing.Middleware("go-template"),
-		context.Contexter(db),
+		context.RequestID(),
+		context.Contexter(db, redisClient),
+		context.Logger(),
 	)
 
 	f.Group("/api", func() {
+		authHandler := NewAuthHandler()
+		f.Group("/auth", func() {
+			f.Post("/login",
+				context.RateLimit(limiter, "login", ratelimit.PerMinute(10), context.RateLimitByIP),
+				form.Bind(form.Login{}),
+				authHandler.Login,
+			)
+			f.Post("/logout", context.SignInRequired, authHandler.Logout)
+		})
 
 		userHandler := NewUserHandler()
 		f.Group("/users", func() {
 			f.Combo("").
-				Get(userHandler.List).
-				Post(form.Bind(form.CreateUser{}), userHandler.Create)
-			f.Combo("/{user_uid}", userHandler.Userer).
-				Get(userHandler.Get).
-				Put(form.Bind(form.UpdateUser{}), userHandler.Update).
-				Delete(userHandler.Delete)
+				Get(context.Require(dbpkg.PermissionUsersRead), userHandler.List).
+				Post(
+					context.Require(dbpkg.PermissionUsersCreate),
+					context.RateLimit(limiter, "create-user", ratelimit.PerMinute(30), context.RateLimitByUser),
+					form.Bind(form.CreateUser{}),
+					userHandler.Create,
+				)
+			f.Combo("/{user_uid}", context.SignInRequired, userHandler.Userer).
+				Get(context.RequireSelfOr(dbpkg.PermissionUsersRead), userHandler.Get).
+				Put(context.RequireSelfOr(dbpkg.PermissionUsersUpdate), form.Bind(form.UpdateUser{}), userHandler.Update).
+				Delete(context.RequireSelfOr(dbpkg.PermissionUsersDelete), userHandler.Delete)
+			f.Post("/{user_uid}/unlock", context.Require(dbpkg.PermissionUsersUpdate), userHandler.Userer, userHandler.Unlock)
 		})
 	})
 
@@ -52,11 +80,35 @@ func New(db *gorm.DB) *flamego.Flame {
 	f.Any("/swagger", func(ctx context.Context) { ctx.Redirect("/swagger/index.html") })
 	f.Any("/swagger/{**}", flamegoswagger.WrapHandler(swaggerfiles.Handler))
 
-	healthz.Set("version", appconst.BuildCommit)
-	healthz.Register("postgres", 10*time.Second, func() error {
-		return dbpkg.Ping(gocontext.Background())
-	})
-	f.Get("/healthz", healthz.Handler())
+	health.SetVersion(appconst.BuildCommit)
+	health.Register("lifecycle", 0, func(gocontext.Context) error {
+		if !lc.Ready() {
+			return errors.New("not ready")
+		}
+		return nil
+	}, health.Readiness)
+	health.Register("started", 0, func(gocontext.Context) error {
+		if !lc.Started() {
+			return errors.New("not started")
+		}
+		return nil
+	}, health.Startup)
+	health.Register("postgres", 0, dbpkg.Ping, health.Readiness, health.Startup)
+	health.Register("migrations", 0, dbpkg.CheckSchema, health.Readiness, health.Startup)
+	if redisClient != nil {
+		health.Register("redis", 0, redis.Ping, health.Readiness, health.Startup)
+	}
+	f.Get("/livez", health.Handler(health.Liveness))
+	f.Get("/readyz", health.Handler(health.Readiness))
+	f.Get("/startupz", health.Handler(health.Startup))
+	// Deprecated: /healthz is kept for the existing probes, use /readyz instead.
+	f.Get("/healthz", health.Handler(health.Readiness))
+
+	// The metrics are only served to the public if they are opted in, see
+	// the admin listener for the operators.
+	if conf.Metrics.Enabled && conf.Metrics.Public {
+		f.Get(conf.Metrics.Path, metrics.Handler())
+	}
 
 	return f
 }
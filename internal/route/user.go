@@ -5,14 +5,14 @@
 package route
 
 import (
-	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 
 	"github.com/wuhan005/go-template/internal/context"
 	"github.com/wuhan005/go-template/internal/db"
 	"github.com/wuhan005/go-template/internal/dbutil"
+	"github.com/wuhan005/go-template/internal/errs"
 	"github.com/wuhan005/go-template/internal/form"
 	"github.com/wuhan005/go-template/internal/response"
 )
@@ -28,21 +28,82 @@ func NewUserHandler() *UserHandler {
 // List
 // @Summary List users
 // @Produce json
-// @Param page query int false "Page number" default(1)
+// @Param pagination query string false "Pagination mode" Enums(offset, cursor) default(offset)
+// @Param page query int false "Page number, offset mode only" default(1)
 // @Param pageSize query int false "Page size" default(20)
+// @Param after query string false "Cursor to list the users after, cursor mode only"
+// @Param before query string false "Cursor to list the users before, cursor mode only"
+// @Param email query string false "Filter by email"
+// @Param nickName query string false "Filter by nickname substring"
+// @Param createdAfter query string false "Filter by creation time, inclusive (RFC 3339)"
+// @Param createdBefore query string false "Filter by creation time, exclusive (RFC 3339)"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending, one of id, email, nickName, createdAt, e.g. -createdAt,nickName. Offset mode only, cursor mode always sorts by -createdAt"
 // @Success 200 {object} response.ListUser
+// @Failure 400 "Invalid query parameters" string
+// @Failure 401 "Unauthorized" string
+// @Failure 403 "Permission denied" string
 // @Failure 500 "Internal server error" string
 // @Router /users [get]
 func (*UserHandler) List(ctx context.Context) error {
+	var createdAfter, createdBefore time.Time
+	if v := ctx.Query("createdAfter"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return errs.Invalid("invalid_query", "Invalid %s: %q", "createdAfter", v)
+		}
+		createdAfter = t
+	}
+	if v := ctx.Query("createdBefore"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return errs.Invalid("invalid_query", "Invalid %s: %q", "createdBefore", v)
+		}
+		createdBefore = t
+	}
+	filter := db.UsersFilter{
+		Email:         ctx.QueryTrim("email"),
+		NickName:      ctx.QueryTrim("nickName"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+	}
+
+	if ctx.Query("pagination") == "cursor" {
+		users, total, cursors, err := db.Users.ListByCursor(ctx.Request().Context(), db.ListUsersByCursorOptions{
+			KeysetPagination: dbutil.KeysetPagination{
+				After:    ctx.Query("after"),
+				Before:   ctx.Query("before"),
+				PageSize: ctx.QueryInt("pageSize", dbutil.DefaultPageSize),
+			},
+			UsersFilter: filter,
+		})
+		if err != nil {
+			return errors.Wrap(err, "list users by cursor")
+		}
+
+		responseUsers := response.ConvertUsers(users)
+		return ctx.Success(response.ListUser{
+			Data:  responseUsers,
+			Total: total,
+			Next:  cursors.Next,
+			Prev:  cursors.Prev,
+		})
+	}
+
+	sort, err := dbutil.ParseSort(ctx.Query("sort"), db.UserSortColumns)
+	if err != nil {
+		return errors.Wrap(err, "parse sort")
+	}
+
 	users, total, err := db.Users.List(ctx.Request().Context(), db.ListUsersOptions{
 		Pagination: dbutil.Pagination{
 			Page:     ctx.QueryInt("page", 1),
 			PageSize: ctx.QueryInt("pageSize", dbutil.DefaultPageSize),
 		},
+		UsersFilter: filter,
+		Sort:        sort,
 	})
 	if err != nil {
-		logrus.WithContext(ctx.Request().Context()).WithError(err).Error("Failed to list users")
-		return ctx.ServerError()
+		return errors.Wrap(err, "list users")
 	}
 
 	responseUsers := response.ConvertUsers(users)
@@ -58,6 +119,10 @@ func (*UserHandler) List(ctx context.Context) error {
 // @Produce json
 // @Param form body form.CreateUser true "User creation form"
 // @Success 200 {object} response.User
+// @Failure 401 "Unauthorized" string
+// @Failure 403 "Permission denied" string
+// @Failure 409 "Email has already been taken" string
+// @Failure 429 "Too many requests" string
 // @Failure 500 "Internal server error" string
 // @Router /users [post]
 func (*UserHandler) Create(ctx context.Context, f form.CreateUser) error {
@@ -67,8 +132,7 @@ func (*UserHandler) Create(ctx context.Context, f form.CreateUser) error {
 		NickName: f.NickName,
 	})
 	if err != nil {
-		logrus.WithContext(ctx.Request().Context()).WithError(err).Error("Failed to create user")
-		return ctx.ServerError()
+		return errors.Wrap(err, "create user")
 	}
 
 	responseUser := response.ConvertUser(user)
@@ -79,12 +143,7 @@ func (*UserHandler) Userer(ctx context.Context) error {
 	userUID := ctx.Param("user_uid")
 	user, err := db.Users.GetByUID(ctx.Request().Context(), userUID)
 	if err != nil {
-		if errors.Is(err, db.ErrUserNotFound) {
-			return ctx.Error(http.StatusNotFound, "User does not exist")
-		}
-
-		logrus.WithContext(ctx.Request().Context()).WithError(err).Error("Failed to get user")
-		return ctx.ServerError()
+		return errors.Wrap(err, "get user")
 	}
 
 	ctx.Map(user)
@@ -96,6 +155,8 @@ func (*UserHandler) Userer(ctx context.Context) error {
 // @Produce json
 // @Param user_uid path string true "User UID"
 // @Success 200 {object} response.User
+// @Failure 401 "Unauthorized" string
+// @Failure 403 "Permission denied" string
 // @Failure 404 "User does not exist" string
 // @Failure 500 "Internal server error" string
 // @Router /users/{user_uid} [get]
@@ -111,6 +172,8 @@ func (*UserHandler) Get(ctx context.Context, user *db.User) error {
 // @Param user_uid path string true "User UID"
 // @Param form body form.UpdateUser true "User update form"
 // @Success 200 "User updated successfully" string
+// @Failure 401 "Unauthorized" string
+// @Failure 403 "Permission denied" string
 // @Failure 404 "User does not exist" string
 // @Failure 500 "Internal server error" string
 // @Router /users/{user_uid} [put]
@@ -118,11 +181,10 @@ func (*UserHandler) Update(ctx context.Context, user *db.User, f form.UpdateUser
 	if err := db.Users.Update(ctx.Request().Context(), user.ID, db.UpdateUserOptions{
 		NickName: f.NickName,
 	}); err != nil {
-		logrus.WithContext(ctx.Request().Context()).WithError(err).Error("Failed to update user")
-		return ctx.ServerError()
+		return errors.Wrap(err, "update user")
 	}
 
-	return ctx.Success("User updated successfully")
+	return ctx.Success(ctx.Tr("user.updated"))
 }
 
 // Delete
@@ -130,13 +192,31 @@ func (*UserHandler) Update(ctx context.Context, user *db.User, f form.UpdateUser
 // @Produce json
 // @Param user_uid path string true "User UID"
 // @Success 200 "User deleted successfully" string
+// @Failure 401 "Unauthorized" string
+// @Failure 403 "Permission denied" string
 // @Failure 404 "User does not exist" string
 // @Failure 500 "Internal server error" string
 // @Router /users/{user_uid} [delete]
 func (*UserHandler) Delete(ctx context.Context, user *db.User) error {
 	if err := db.Users.Delete(ctx.Request().Context(), user.ID); err != nil {
-		logrus.WithContext(ctx.Request().Context()).WithError(err).Error("Failed to delete user")
-		return ctx.ServerError()
+		return errors.Wrap(err, "delete user")
+	}
+	return ctx.Success(ctx.Tr("user.deleted"))
+}
+
+// Unlock
+// @Summary Unlock a user locked for too many failed sign-in attempts
+// @Produce json
+// @Param user_uid path string true "User UID"
+// @Success 200 "User unlocked successfully" string
+// @Failure 401 "Unauthorized" string
+// @Failure 403 "Permission denied" string
+// @Failure 404 "User does not exist" string
+// @Failure 500 "Internal server error" string
+// @Router /users/{user_uid}/unlock [post]
+func (*UserHandler) Unlock(ctx context.Context, user *db.User) error {
+	if err := db.LoginAttempts.Reset(ctx.Request().Context(), db.EmailLoginKey(user.Email)); err != nil {
+		return errors.Wrap(err, "reset failed sign-in attempts")
 	}
-	return ctx.Success("User deleted successfully")
+	return ctx.Success(ctx.Tr("user.unlocked"))
 }
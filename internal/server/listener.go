@@ -0,0 +1,100 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+// Listen returns the listener of the server, which is in the order of:
+//  1. The first socket passed by systemd socket activation.
+//  2. The configured Unix socket.
+//  3. The TCP address.
+func Listen(address string) (net.Listener, error) {
+	listener, err := systemdListener()
+	if err != nil {
+		return nil, errors.Wrap(err, "systemd socket activation")
+	}
+	if listener != nil {
+		return listener, nil
+	}
+
+	if conf.Server.UnixSocket != "" {
+		return listenUnix(conf.Server.UnixSocket, conf.Server.UnixSocketMode)
+	}
+	return net.Listen("tcp", address)
+}
+
+// systemdListener returns the listener of the socket passed by systemd, or
+// nil if the process is not socket-activated. See sd_listen_fds(3).
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, nil
+	}
+
+	// Unset the variables so that they are not inherited by the child processes.
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(listenFDsStart, "LISTEN_FD_3")
+	defer func() { _ = file.Close() }()
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "file listener")
+	}
+	return listener, nil
+}
+
+// listenUnix listens on the Unix socket of the path with the file mode. The
+// stale socket left by a previous process is removed, but the socket that is
+// still accepting connections is not taken over.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, errors.Errorf("%q exists and is not a socket", path)
+		}
+
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			_ = conn.Close()
+			return nil, errors.Errorf("%q is already in use", path)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, errors.Wrap(err, "dial existing socket")
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, errors.Wrap(err, "remove stale socket")
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "listen")
+	}
+	// The mode is changed after the socket is created rather than by the umask,
+	// which is process-wide and would affect the files created concurrently.
+	if err := os.Chmod(path, mode); err != nil {
+		_ = listener.Close()
+		return nil, errors.Wrap(err, "change mode")
+	}
+	return listener, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	t.Run("mode", func(t *testing.T) {
+		// The modes both narrower and wider than the default umask are set.
+		for _, mode := range []os.FileMode{0o600, 0o660, 0o666} {
+			path := filepath.Join(t.TempDir(), "server.sock")
+			listener, err := listenUnix(path, mode)
+			require.NoError(t, err)
+
+			fi, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, mode, fi.Mode().Perm())
+			_ = listener.Close()
+		}
+	})
+
+	t.Run("stale socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "server.sock")
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		// Leave the socket file behind like a crashed process.
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+
+		listener, err := listenUnix(path, 0o660)
+		require.NoError(t, err)
+		_ = listener.Close()
+	})
+
+	t.Run("socket in use", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "server.sock")
+		live, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		defer func() { _ = live.Close() }()
+
+		_, err = listenUnix(path, 0o660)
+		assert.ErrorContains(t, err, "already in use")
+		_, err = os.Stat(path)
+		assert.NoError(t, err)
+	})
+
+	t.Run("not a socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "server.sock")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		_, err := listenUnix(path, 0o660)
+		assert.ErrorContains(t, err, "is not a socket")
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package server builds the HTTP server and its listener from the configuration.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// New returns a new HTTP server of the handler with the configured timeouts,
+// protocols and TLS.
+func New(handler http.Handler) (*http.Server, error) {
+	server := &http.Server{
+		Handler:           handler,
+		ReadTimeout:       conf.Server.ReadTimeout,
+		ReadHeaderTimeout: conf.Server.ReadHeaderTimeout,
+		WriteTimeout:      conf.Server.WriteTimeout,
+		IdleTimeout:       conf.Server.IdleTimeout,
+		MaxHeaderBytes:    conf.Server.MaxHeaderBytes,
+		Protocols:         new(http.Protocols),
+	}
+
+	server.Protocols.SetHTTP1(true)
+	if conf.Server.HTTP2 {
+		server.Protocols.SetHTTP2(true)
+		// HTTP/2 without TLS is only used behind the proxies that speak it.
+		server.Protocols.SetUnencryptedHTTP2(conf.Server.H2C)
+	}
+
+	if TLSEnabled() {
+		tlsConfig, err := newTLSConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "new TLS config")
+		}
+		server.TLSConfig = tlsConfig
+	}
+	return server, nil
+}
+
+// TLSEnabled reports whether the server serves TLS.
+func TLSEnabled() bool {
+	return conf.Server.TLSCertFile != ""
+}
+
+// Serve accepts the connections on the listener, with TLS if it is enabled.
+func Serve(server *http.Server, listener net.Listener) error {
+	if server.TLSConfig != nil {
+		// The certificate is loaded by the GetCertificate of the TLS config.
+		return server.ServeTLS(listener, "", "")
+	}
+	return server.Serve(listener)
+}
+
+// Shutdown returns the shutdown phase of the server, which gracefully shuts it
+// down, and closes the remaining connections if it times out, so that the
+// requests still running don't outlive the phases after it, e.g. closing the
+// database.
+func Shutdown(server *http.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		err := server.Shutdown(ctx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			if closeErr := server.Close(); closeErr != nil {
+				return errors.Wrap(closeErr, "close")
+			}
+		}
+		return err
+	}
+}
+
+// tlsVersions is the supported minimum TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// newTLSConfig returns the TLS config with the certificate reloaded on change,
+// and the client certificates verified if the client CA is configured.
+func newTLSConfig() (*tls.Config, error) {
+	minVersion, ok := tlsVersions[conf.Server.TLSMinVersion]
+	if !ok {
+		return nil, errors.Errorf("unsupported minimum TLS version %q, expect one of 1.2, 1.3", conf.Server.TLSMinVersion)
+	}
+
+	reloader, err := newCertReloader(conf.Server.TLSCertFile, conf.Server.TLSKeyFile, conf.Server.TLSReloadInterval)
+	if err != nil {
+		return nil, errors.Wrap(err, "load certificate")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if conf.Server.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(conf.Server.TLSClientCAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "read client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificate found in client CA file %q", conf.Server.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+
+		switch conf.Server.TLSClientAuth {
+		case "require":
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		case "optional":
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, errors.Errorf("unsupported client auth %q, expect one of require, optional", conf.Server.TLSClientAuth)
+		}
+	}
+	return tlsConfig, nil
+}
+
+// certReloader serves the certificate of the files, which is reloaded when
+// the modification time of any of them changes. The files are checked at
+// most once per interval on the TLS handshakes.
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// latestModTime returns the latest modification time of the files.
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "stat")
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "load key pair")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. The previous
+// certificate is kept if the reload fails, e.g. when the files are being
+// replaced.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, modTime, due := r.cert, r.modTime, r.interval > 0 && time.Since(r.checkedAt) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return cert, nil
+	}
+
+	r.mu.Lock()
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+
+	latest, err := r.latestModTime()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to check TLS certificate")
+		return cert, nil
+	}
+	if latest.Equal(modTime) {
+		return cert, nil
+	}
+
+	if err := r.load(latest); err != nil {
+		logrus.WithError(err).Error("Failed to reload TLS certificate")
+		return cert, nil
+	}
+	logrus.WithField("cert_file", r.certFile).Info("Reloaded TLS certificate")
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wuhan005/go-template/internal/conf"
+)
+
+// testCert is a certificate and its key for the tests.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate of the template, which is signed by the
+// parent, or self-signed if the parent is nil.
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	issuer, issuerKey := template, key
+	if parent != nil {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func newTestCA(t *testing.T, name string) *testCert {
+	t.Helper()
+	return newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+}
+
+func newServerCert(t *testing.T, ca *testCert, name string) *testCert {
+	t.Helper()
+	return newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+}
+
+func newClientCert(t *testing.T, ca *testCert, name string) *testCert {
+	t.Helper()
+	return newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+}
+
+// writeFiles writes the certificate and the key in PEM to the files.
+func (c *testCert) writeFiles(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+// tlsCertificate returns the certificate to be presented by a client.
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := newTestCA(t, "ca")
+	first := newServerCert(t, ca, "first")
+	first.writeFiles(t, certFile, keyFile)
+
+	reloader, err := newCertReloader(certFile, keyFile, time.Millisecond)
+	require.NoError(t, err)
+	got, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.cert.Raw, got.Certificate[0])
+
+	// Rotate the files, whose modification time is set explicitly as the
+	// resolution of the file system may be coarse.
+	second := newServerCert(t, ca, "second")
+	second.writeFiles(t, certFile, keyFile)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	time.Sleep(2 * time.Millisecond)
+
+	got, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, got.Certificate[0])
+
+	// The previous certificate is kept if the files are broken.
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	evenLater := later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, evenLater, evenLater))
+	time.Sleep(2 * time.Millisecond)
+
+	got, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.cert.Raw, got.Certificate[0])
+}
+
+// handshake runs a TLS handshake between the server and the client configs
+// over a loopback connection, and returns the error of the server side, or of
+// the client side if the server succeeds.
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) error {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		serverErr <- conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, clientErr := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if clientErr == nil {
+		_ = conn.Close()
+	}
+	if err := <-serverErr; err != nil {
+		return err
+	}
+	return clientErr
+}
+
+func TestNewTLSConfig_ClientCA(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	clientCAFile := filepath.Join(dir, "client-ca.crt")
+
+	serverCA := newTestCA(t, "server ca")
+	newServerCert(t, serverCA, "server").writeFiles(t, certFile, keyFile)
+	clientCA := newTestCA(t, "client ca")
+	require.NoError(t, os.WriteFile(clientCAFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientCA.cert.Raw}), 0o600))
+
+	trusted := newClientCert(t, clientCA, "trusted").tlsCertificate()
+	untrusted := newClientCert(t, newTestCA(t, "other ca"), "untrusted").tlsCertificate()
+
+	serverConf := conf.Server
+	t.Cleanup(func() { conf.Server = serverConf })
+	conf.Server.TLSMinVersion = "1.2"
+	conf.Server.TLSCertFile = certFile
+	conf.Server.TLSKeyFile = keyFile
+	conf.Server.TLSClientCAFile = clientCAFile
+
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCA.cert)
+	clientConfig := func(certs ...tls.Certificate) *tls.Config {
+		return &tls.Config{
+			RootCAs:    roots,
+			ServerName: "localhost",
+			// Present the certificate even if it is not issued by the CAs the
+			// server accepts, which the client would skip otherwise.
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if len(certs) == 0 {
+					return &tls.Certificate{}, nil
+				}
+				return &certs[0], nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		clientAuth string
+		certs      []tls.Certificate
+		wantErr    bool
+	}{
+		{name: "require trusted", clientAuth: "require", certs: []tls.Certificate{trusted}},
+		{name: "require without certificate", clientAuth: "require", wantErr: true},
+		{name: "require untrusted", clientAuth: "require", certs: []tls.Certificate{untrusted}, wantErr: true},
+		{name: "optional trusted", clientAuth: "optional", certs: []tls.Certificate{trusted}},
+		{name: "optional without certificate", clientAuth: "optional"},
+		{name: "optional untrusted", clientAuth: "optional", certs: []tls.Certificate{untrusted}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conf.Server.TLSClientAuth = tc.clientAuth
+			serverConfig, err := newTLSConfig()
+			require.NoError(t, err)
+
+			err = handshake(t, serverConfig, clientConfig(tc.certs...))
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("no certificate in CA file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(clientCAFile, []byte("not a certificate"), 0o600))
+		_, err := newTLSConfig()
+		assert.ErrorContains(t, err, "no certificate found in client CA file")
+	})
+}
@@ -7,9 +7,12 @@ package tracing
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/flamego/flamego"
-
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -18,6 +21,60 @@ import (
 // instrumentationName is the name of this instrumentation package.
 const instrumentationName = "github.com/wuhan005/go-template/internal/tracing"
 
+// serverMetrics is the set of HTTP server instruments, following the OpenTelemetry
+// semantic conventions of HTTP metrics.
+type serverMetrics struct {
+	requestDuration  metric.Float64Histogram
+	activeRequests   metric.Int64UpDownCounter
+	requestBodySize  metric.Int64Histogram
+	responseBodySize metric.Int64Histogram
+}
+
+func newServerMetrics(meter metric.Meter) (*serverMetrics, error) {
+	requestDuration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create request duration histogram: %w", err)
+	}
+	activeRequests, err := meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithDescription("Number of active HTTP server requests."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create active requests counter: %w", err)
+	}
+	requestBodySize, err := meter.Int64Histogram("http.server.request.body.size",
+		metric.WithDescription("Size of HTTP server request bodies."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create request body size histogram: %w", err)
+	}
+	responseBodySize, err := meter.Int64Histogram("http.server.response.body.size",
+		metric.WithDescription("Size of HTTP server response bodies."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create response body size histogram: %w", err)
+	}
+
+	return &serverMetrics{
+		requestDuration:  requestDuration,
+		activeRequests:   activeRequests,
+		requestBodySize:  requestBodySize,
+		responseBodySize: responseBodySize,
+	}, nil
+}
+
+// Route returns the matched route pattern of the request, e.g.
+// "/api/users/{user_uid}", or empty if no route is matched.
+func Route(c flamego.Context) string {
+	return c.Param("route")
+}
+
 // Middleware returns a flamego Handler to trace requests to the server.
 func Middleware(service string, opts ...Option) flamego.Handler {
 	cfg := newConfig(opts)
@@ -25,23 +82,37 @@ func Middleware(service string, opts ...Option) flamego.Handler {
 		instrumentationName,
 		oteltrace.WithInstrumentationVersion("1.0.0"),
 	)
+	meter := cfg.MeterProvider.Meter(
+		instrumentationName,
+		metric.WithInstrumentationVersion("1.0.0"),
+	)
+	metrics, err := newServerMetrics(meter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create HTTP server metrics")
+	}
+
 	return func(res http.ResponseWriter, req *http.Request, c flamego.Context) {
+		start := time.Now()
 		savedCtx := c.Request().Context()
 		defer func() {
 			c.Request().Request = c.Request().WithContext(savedCtx)
 		}()
 
+		// Use the route pattern instead of the actual request path to keep the
+		// cardinality low, e.g. "/api/users/{user_uid}" vs "/api/users/123".
+		route := Route(c)
+		method := c.Request().Method
+
 		ctx := cfg.Propagators.Extract(savedCtx, propagation.HeaderCarrier(c.Request().Header))
 		opts := []oteltrace.SpanStartOption{
 			oteltrace.WithAttributes(semconv.NetAttributesFromHTTPRequest("tcp", c.Request().Request)...),
 			oteltrace.WithAttributes(semconv.EndUserAttributesFromHTTPRequest(c.Request().Request)...),
-			oteltrace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, "", c.Request().Request)...),
+			oteltrace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(service, route, c.Request().Request)...),
 			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
 		}
-		// TODO: span name should be router template not the actual request path, eg /user/:id vs /user/123
-		spanName := c.Request().RequestURI
-		if spanName == "" {
-			spanName = fmt.Sprintf("HTTP %s route not found", c.Request().Method)
+		spanName := method + " " + route
+		if route == "" {
+			spanName = fmt.Sprintf("HTTP %s route not found", method)
 		}
 		ctx, span := tracer.Start(ctx, spanName, opts...)
 		defer span.End()
@@ -49,13 +120,31 @@ func Middleware(service string, opts ...Option) flamego.Handler {
 		// pass the span through the request context
 		c.Request().Request = c.Request().WithContext(ctx)
 
+		attrs := []attribute.KeyValue{
+			attribute.String("http.request.method", method),
+			attribute.String("http.route", route),
+		}
+		if metrics != nil {
+			metrics.activeRequests.Add(ctx, 1, metric.WithAttributes(attrs...))
+			defer metrics.activeRequests.Add(ctx, -1, metric.WithAttributes(attrs...))
+		}
+
 		// serve the request to the next middleware
 		c.Next()
 
 		status := c.ResponseWriter().Status()
-		attrs := semconv.HTTPAttributesFromHTTPStatusCode(status)
+		spanAttrs := semconv.HTTPAttributesFromHTTPStatusCode(status)
 		spanStatus, spanMessage := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, oteltrace.SpanKindServer)
-		span.SetAttributes(attrs...)
+		span.SetAttributes(spanAttrs...)
 		span.SetStatus(spanStatus, spanMessage)
+
+		if metrics != nil {
+			recordOpt := metric.WithAttributes(append(attrs, attribute.Int("http.response.status_code", status))...)
+			metrics.requestDuration.Record(ctx, time.Since(start).Seconds(), recordOpt)
+			if size := c.Request().ContentLength; size >= 0 {
+				metrics.requestBodySize.Record(ctx, size, recordOpt)
+			}
+			metrics.responseBodySize.Record(ctx, int64(c.ResponseWriter().Size()), recordOpt)
+		}
 	}
 }
@@ -0,0 +1,119 @@
+// Copyright 2025 E99p1ant. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/flamego/flamego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	f := flamego.New()
+	f.Use(Middleware("go-template", WithTracerProvider(tracerProvider), WithMeterProvider(meterProvider)))
+	f.Get("/api/users/{user_uid}", func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/users/123", strings.NewReader(`{"name":"x"}`))
+	f.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	t.Run("span", func(t *testing.T) {
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		span := spans[0]
+		assert.Equal(t, "GET /api/users/{user_uid}", span.Name())
+		assert.Equal(t, oteltrace.SpanKindServer, span.SpanKind())
+		assert.Contains(t, span.Attributes(), attribute.String("http.route", "/api/users/{user_uid}"))
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		require.Len(t, rm.ScopeMetrics, 1)
+
+		got := make(map[string]metricdata.Metrics)
+		for _, m := range rm.ScopeMetrics[0].Metrics {
+			got[m.Name] = m
+		}
+
+		route := attribute.String("http.route", "/api/users/{user_uid}")
+		method := attribute.String("http.request.method", http.MethodGet)
+		status := attribute.Int("http.response.status_code", http.StatusOK)
+
+		duration, ok := got["http.server.request.duration"].Data.(metricdata.Histogram[float64])
+		require.True(t, ok)
+		require.Len(t, duration.DataPoints, 1)
+		assert.Equal(t, uint64(1), duration.DataPoints[0].Count)
+		assertAttributes(t, duration.DataPoints[0].Attributes, route, method, status)
+
+		active, ok := got["http.server.active_requests"].Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+		require.Len(t, active.DataPoints, 1)
+		assert.Equal(t, int64(0), active.DataPoints[0].Value)
+		assertAttributes(t, active.DataPoints[0].Attributes, route, method)
+
+		requestSize, ok := got["http.server.request.body.size"].Data.(metricdata.Histogram[int64])
+		require.True(t, ok)
+		require.Len(t, requestSize.DataPoints, 1)
+		assert.Equal(t, int64(len(`{"name":"x"}`)), requestSize.DataPoints[0].Sum)
+		assertAttributes(t, requestSize.DataPoints[0].Attributes, route, method, status)
+
+		responseSize, ok := got["http.server.response.body.size"].Data.(metricdata.Histogram[int64])
+		require.True(t, ok)
+		require.Len(t, responseSize.DataPoints, 1)
+		assert.Equal(t, int64(len("hello")), responseSize.DataPoints[0].Sum)
+		assertAttributes(t, responseSize.DataPoints[0].Attributes, route, method, status)
+	})
+}
+
+func TestMiddleware_RouteNotFound(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	f := flamego.New()
+	f.Use(Middleware("go-template", WithTracerProvider(tracerProvider), WithMeterProvider(sdkmetric.NewMeterProvider())))
+	f.NotFound(func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	resp := httptest.NewRecorder()
+	f.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	require.Equal(t, http.StatusNotFound, resp.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "HTTP GET route not found", spans[0].Name())
+}
+
+func assertAttributes(t *testing.T, set attribute.Set, want ...attribute.KeyValue) {
+	t.Helper()
+	for _, kv := range want {
+		got, ok := set.Value(kv.Key)
+		if assert.True(t, ok, "missing attribute %s", kv.Key) {
+			assert.Equal(t, kv.Value, got, "attribute %s", kv.Key)
+		}
+	}
+}
@@ -6,21 +6,44 @@ package tracing
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
-	"time"
+	"fmt"
+	"os"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/wuhan005/go-template/internal/conf"
+	"github.com/wuhan005/go-template/internal/metrics"
 )
 
+// Supported exporters.
+const (
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterStdout   = "stdout"
+	ExporterNone     = "none"
+)
+
+// SetupOTelSDK sets up the global tracer and meter providers according to the
+// configuration. The exporter is disabled if it is none, or no endpoint is
+// configured for the OTLP exporters, in which case the global no-op tracer
+// provider is kept, and the metrics are only exposed to Prometheus if enabled.
+// The returned shutdown function flushes and stops the providers.
 func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, err error) {
 	var shutdownFuncs []func(context.Context) error
 
@@ -37,15 +60,41 @@ func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, er
 		err = errors.Join(inErr, shutdown(ctx))
 	}
 
-	tracerProvider, err := newTraceProvider(ctx)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter := conf.Tracing.Exporter
+	switch exporter {
+	case ExporterNone:
+	case ExporterOTLPGRPC, ExporterOTLPHTTP:
+		if conf.Tracing.Endpoint == "" {
+			exporter = ExporterNone
+		}
+	case ExporterStdout:
+	default:
+		return shutdown, fmt.Errorf("unsupported exporter %q", exporter)
+	}
+	if exporter == ExporterNone && !conf.Metrics.Enabled {
+		return shutdown, nil
+	}
+
+	r, err := newResource(ctx)
 	if err != nil {
 		handleErr(err)
 		return
 	}
-	shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
-	otel.SetTracerProvider(tracerProvider)
 
-	meterProvider, err := newMeterProvider()
+	if exporter != ExporterNone {
+		var tracerProvider *trace.TracerProvider
+		tracerProvider, err = newTraceProvider(ctx, exporter, r)
+		if err != nil {
+			handleErr(err)
+			return
+		}
+		shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
+		otel.SetTracerProvider(tracerProvider)
+	}
+
+	meterProvider, err := newMeterProvider(ctx, exporter, r)
 	if err != nil {
 		handleErr(err)
 		return
@@ -55,47 +104,140 @@ func SetupOTelSDK(ctx context.Context) (shutdown func(context.Context) error, er
 	return
 }
 
-func newTraceProvider(ctx context.Context) (*trace.TracerProvider, error) {
-	opts := []otlptracegrpc.Option{
-		otlptracegrpc.WithEndpoint(conf.Tracing.Endpoint),
-		otlptracegrpc.WithInsecure(),
-	}
-	exporter, err := otlptracegrpc.New(ctx, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	r, err := resource.New(ctx, []resource.Option{
+func newResource(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx, []resource.Option{
 		resource.WithAttributes(
 			attribute.KeyValue{Key: "token", Value: attribute.StringValue(conf.Tracing.Token)},
 			attribute.KeyValue{Key: "service.name", Value: attribute.StringValue(conf.Tracing.ServiceName)},
 			attribute.KeyValue{Key: "host.name", Value: attribute.StringValue(conf.Tracing.HostName)}, // <hostName>替换为IP地址
 		),
 	}...)
+}
+
+// newTLSConfig returns the TLS configuration of the OTLP exporters, using the
+// configured CA certificate if any.
+func newTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if conf.Tracing.CAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(conf.Tracing.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no valid certificate found in CA file")
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+func newTraceExporter(ctx context.Context, exporter string) (trace.SpanExporter, error) {
+	switch exporter {
+	case ExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(conf.Tracing.Endpoint)}
+		if conf.Tracing.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(conf.Tracing.Endpoint)}
+		if conf.Tracing.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	default:
+		return stdouttrace.New()
+	}
+}
+
+func newTraceProvider(ctx context.Context, exporter string, r *resource.Resource) (*trace.TracerProvider, error) {
+	spanExporter, err := newTraceExporter(ctx, exporter)
 	if err != nil {
 		return nil, err
 	}
 
 	tp := trace.NewTracerProvider(
-		trace.WithSampler(trace.AlwaysSample()),
-		trace.WithBatcher(exporter),
+		trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(conf.Tracing.SamplerRatio))),
+		trace.WithBatcher(spanExporter),
 		trace.WithResource(r),
 	)
-	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 	return tp, nil
 }
 
-func newMeterProvider() (*metric.MeterProvider, error) {
-	metricExporter, err := stdoutmetric.New()
-	if err != nil {
-		return nil, err
+func newMetricExporter(ctx context.Context, exporter string) (metric.Exporter, error) {
+	switch exporter {
+	case ExporterOTLPGRPC:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(conf.Tracing.Endpoint)}
+		if conf.Tracing.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+
+	case ExporterOTLPHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(conf.Tracing.Endpoint)}
+		if conf.Tracing.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		} else {
+			tlsConfig, err := newTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+
+	default:
+		return stdoutmetric.New()
 	}
+}
 
-	meterProvider := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(metricExporter,
-			// Default is 1m. Set to 3s for demonstrative purposes.
-			metric.WithInterval(3*time.Second))),
-	)
-	return meterProvider, nil
+// newMeterProvider returns the meter provider which pushes the metrics to the
+// exporter unless it is none, and exposes them to Prometheus if enabled.
+func newMeterProvider(ctx context.Context, exporter string, r *resource.Resource) (*metric.MeterProvider, error) {
+	opts := []metric.Option{metric.WithResource(r)}
+
+	if exporter != ExporterNone {
+		metricExporter, err := newMetricExporter(ctx, exporter)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(metricExporter)))
+	}
+
+	if conf.Metrics.Enabled {
+		// The target info is left out as the resource contains the token.
+		reader, err := otelprometheus.New(
+			otelprometheus.WithRegisterer(metrics.Registry),
+			otelprometheus.WithoutTargetInfo(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create prometheus exporter: %w", err)
+		}
+		opts = append(opts, metric.WithReader(reader))
+	}
+
+	return metric.NewMeterProvider(opts...), nil
 }